@@ -0,0 +1,197 @@
+// Package align compares blocks of tiles by alignment and classifies
+// differences into edits.
+package align
+
+// EditType is the type of an edit.
+type EditType int
+
+const (
+	ET_SNV EditType = iota // Single nucleotide variant.
+	ET_INS                 // Insertion.
+	ET_DEL                 // Deletion.
+	ET_MNP                 // Multiple nucleotide polymorphism.
+)
+
+var editTypeNames = [...]string{"snv", "ins", "del", "mnp"}
+
+// String returns name of EditType in lower case.
+func (et EditType) String() string {
+	if et < 0 || int(et) >= len(editTypeNames) {
+		return "unknown"
+	}
+	return editTypeNames[et]
+}
+
+// parseEditType returns EditType of name, it returns false when name is unknown.
+func parseEditType(name string) (EditType, bool) {
+	for i, n := range editTypeNames {
+		if n == name {
+			return EditType(i), true
+		}
+	}
+	return 0, false
+}
+
+// Edit represents a change from reference block, offset is relative to the
+// start of reference block. Ref is empty for insertion and Alt is empty for deletion.
+type Edit struct {
+	Type     EditType
+	Offset   int
+	Ref, Alt []byte
+}
+
+// Options represents thresholds of blocks that are not complex.
+type Options struct {
+	// Maximum edit distance, a substitution counts as a deletion and an insertion.
+	MaxDistance int
+	// Maximum number of edits.
+	MaxEdits int
+}
+
+// DefaultOptions is the default thresholds of comparing blocks.
+var DefaultOptions = Options{MaxDistance: 16, MaxEdits: 4}
+
+const (
+	opEqual = iota
+	opDelete
+	opInsert
+)
+
+// script computes the shortest edit script from a to b by Myers' O(ND)
+// algorithm, it returns false when the edit distance exceeds max.
+func script(a, b []byte, max int) ([]byte, bool) {
+	n, m := len(a), len(b)
+	if max > n+m {
+		max = n + m
+	}
+	if max < 0 {
+		return nil, false
+	}
+
+	off := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int, 0, max+1)
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, off, n, m), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// backtrack walks trace of V arrays back from (n, m) and returns the edit script.
+func backtrack(trace [][]int, off, n, m int) []byte {
+	ops := make([]byte, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v, k := trace[d], x-y
+		prevK := k - 1
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, opEqual)
+			x--
+			y--
+		}
+		if prevK == k+1 {
+			ops = append(ops, opInsert)
+		} else {
+			ops = append(ops, opDelete)
+		}
+		x, y = prevX, prevY
+	}
+	for ; x > 0; x-- {
+		ops = append(ops, opEqual)
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// Compare aligns alt against ref and returns edits in order of offsets.
+// It returns true as complex when changes exceed thresholds of opt,
+// bases of edits share memory with ref and alt.
+func Compare(ref, alt []byte, opt Options) ([]Edit, bool) {
+	ops, ok := script(ref, alt, opt.MaxDistance)
+	if !ok {
+		return nil, true
+	}
+
+	var edits []Edit
+	// End of last run of changes in ref.
+	x, y, last := 0, 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i] == opEqual {
+			x, y = x+1, y+1
+			i++
+			continue
+		}
+
+		// Collect a run of changes, deleted and inserted bases are
+		// contiguous in ref and alt respectively.
+		x0, y0 := x, y
+		for ; i < len(ops) && ops[i] != opEqual; i++ {
+			if ops[i] == opDelete {
+				x++
+			} else {
+				y++
+			}
+		}
+		edits = appendRun(edits, ref, alt, last, x0, x, y0, y)
+		last = x
+	}
+	if len(edits) > opt.MaxEdits {
+		return nil, true
+	}
+	return edits, false
+}
+
+// appendRun appends edits of ref[x0:x1] being replaced by alt[y0:y1], the
+// overlapped length is substitution and the rest is insertion or deletion.
+// Bases from bound to x0 are equal, and pure insertion or deletion is shifted
+// to the left most position within them.
+func appendRun(edits []Edit, ref, alt []byte, bound, x0, x1, y0, y1 int) []Edit {
+	sub := x1 - x0
+	if y1-y0 < sub {
+		sub = y1 - y0
+	}
+	if sub == 0 {
+		for x0 > bound && ((x1 > x0 && ref[x0-1] == ref[x1-1]) || (y1 > y0 && alt[y0-1] == alt[y1-1])) {
+			x0, x1, y0, y1 = x0-1, x1-1, y0-1, y1-1
+		}
+	}
+	switch {
+	case sub == 1:
+		edits = append(edits, Edit{ET_SNV, x0, ref[x0 : x0+1], alt[y0 : y0+1]})
+	case sub > 1:
+		edits = append(edits, Edit{ET_MNP, x0, ref[x0 : x0+sub], alt[y0 : y0+sub]})
+	}
+	x0, y0 = x0+sub, y0+sub
+	switch {
+	case x1 > x0:
+		edits = append(edits, Edit{ET_DEL, x0, ref[x0:x1], []byte{}})
+	case y1 > y0:
+		edits = append(edits, Edit{ET_INS, x0, []byte{}, alt[y0:y1]})
+	}
+	return edits
+}
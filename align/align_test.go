@@ -0,0 +1,80 @@
+package align
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompare(t *testing.T) {
+	Convey("Compare blocks by alignment", t, func() {
+		testCases := []struct {
+			ref, alt string
+			edits    []Edit
+		}{
+			{"ACGTACGT", "ACGTACGT", nil},
+			{"ACGTACGT", "ACCTACGT", []Edit{{ET_SNV, 2, []byte("G"), []byte("C")}}},
+			{"ACGTACGT", "ACGTTTGT", []Edit{{ET_MNP, 4, []byte("AC"), []byte("TT")}}},
+			{"ACGTACGT", "ACGTTACGT", []Edit{{ET_INS, 3, []byte{}, []byte("T")}}},
+			{"ACGTACGT", "ACGCGT", []Edit{{ET_DEL, 3, []byte("TA"), []byte{}}}},
+			{"ACGTACGT", "TCGTACGA", []Edit{
+				{ET_SNV, 0, []byte("A"), []byte("T")},
+				{ET_SNV, 7, []byte("T"), []byte("A")},
+			}},
+			{"ACGTTTTACGT", "ACGTTTACGT", []Edit{{ET_DEL, 3, []byte("T"), []byte{}}}},
+			{"AAAACCCC", "AAAAGGGGGCCCC", []Edit{{ET_INS, 4, []byte{}, []byte("GGGGG")}}},
+		}
+		for _, tc := range testCases {
+			edits, isComplex := Compare([]byte(tc.ref), []byte(tc.alt), DefaultOptions)
+			So(isComplex, ShouldBeFalse)
+			So(edits, ShouldResemble, tc.edits)
+		}
+
+		Convey("Changes exceed thresholds", func() {
+			_, isComplex := Compare([]byte("ACGTACGT"), []byte("TGCATGCA"), Options{MaxDistance: 4, MaxEdits: 4})
+			So(isComplex, ShouldBeTrue)
+			_, isComplex = Compare([]byte("ACGTACGT"), []byte("TCCTTCGA"), Options{MaxDistance: 16, MaxEdits: 3})
+			So(isComplex, ShouldBeTrue)
+		})
+	})
+}
+
+func TestScript(t *testing.T) {
+	Convey("Shortest edit script is applied to get target", t, func() {
+		for _, tc := range [][2]string{
+			{"", ""}, {"", "AC"}, {"AC", ""}, {"ABCABBA", "CBABAC"}, {"GATTACA", "GCATGCU"},
+		} {
+			a, b := []byte(tc[0]), []byte(tc[1])
+			ops, ok := script(a, b, len(a)+len(b))
+			So(ok, ShouldBeTrue)
+
+			var out []byte
+			x, y := 0, 0
+			for _, op := range ops {
+				switch op {
+				case opEqual:
+					out = append(out, a[x])
+					x, y = x+1, y+1
+				case opDelete:
+					x++
+				case opInsert:
+					out = append(out, b[y])
+					y++
+				}
+			}
+			So(string(out), ShouldEqual, tc[1])
+			So(x, ShouldEqual, len(a))
+		}
+
+		ops, _ := script([]byte("ABCABBA"), []byte("CBABAC"), 10)
+		d := 0
+		for _, op := range ops {
+			if op != opEqual {
+				d++
+			}
+		}
+		So(d, ShouldEqual, 5)
+		_, ok := script([]byte("ABCABBA"), []byte("CBABAC"), 4)
+		So(ok, ShouldBeFalse)
+	})
+}
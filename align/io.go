@@ -0,0 +1,125 @@
+package align
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// TileEdits represents edits of a tile in bit sequence, and the index of
+// reference block that is compared with.
+type TileEdits struct {
+	Tile  uint64
+	Ref   int
+	Edits []Edit
+}
+
+const editsHeader = "tile\tref\toffset\ttype\tref_bases\talt_bases"
+
+// bases returns "-" for empty bases.
+func bases(b []byte) string {
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// WriteEdits writes edits of tiles in TSV format, one edit per line.
+func WriteEdits(w io.Writer, tes []TileEdits) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, editsHeader)
+	for _, te := range tes {
+		for _, e := range te.Edits {
+			fmt.Fprintf(bw, "%d\t%d\t%d\t%s\t%s\t%s\n", te.Tile, te.Ref, e.Offset,
+				e.Type, bases(e.Ref), bases(e.Alt))
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadEdits reads edits of tiles in TSV format that is written by WriteEdits.
+func ReadEdits(r io.Reader) ([]TileEdits, error) {
+	var tes []TileEdits
+	snr := bufio.NewScanner(r)
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if line == 1 {
+			if text != editsHeader {
+				return nil, fmt.Errorf("align: line %d: invalid header: %s", line, text)
+			}
+			continue
+		} else if len(text) == 0 {
+			continue
+		}
+
+		infos := strings.Split(text, "\t")
+		if len(infos) != 6 {
+			return nil, fmt.Errorf("align: line %d: expect 6 fields but got %d", line, len(infos))
+		}
+		tile, err := strconv.ParseUint(infos[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("align: line %d: %v", line, err)
+		}
+		ref, err := strconv.Atoi(infos[1])
+		if err != nil {
+			return nil, fmt.Errorf("align: line %d: %v", line, err)
+		}
+		e := Edit{}
+		if e.Offset, err = strconv.Atoi(infos[2]); err != nil {
+			return nil, fmt.Errorf("align: line %d: %v", line, err)
+		}
+		var ok bool
+		if e.Type, ok = parseEditType(infos[3]); !ok {
+			return nil, fmt.Errorf("align: line %d: unknown edit type: %s", line, infos[3])
+		}
+		e.Ref, e.Alt = []byte(strings.TrimPrefix(infos[4], "-")), []byte(strings.TrimPrefix(infos[5], "-"))
+
+		if n := len(tes); n == 0 || tes[n-1].Tile != tile {
+			tes = append(tes, TileEdits{Tile: tile, Ref: ref})
+		}
+		tes[len(tes)-1].Edits = append(tes[len(tes)-1].Edits, e)
+	}
+	return tes, snr.Err()
+}
+
+// WriteVCF writes edits of tiles as haploid calls of sample in VCF format,
+// positions are computed by tiles of reference blocks. Insertions and deletions
+// are anchored by the base before, or the base after at start of block.
+func WriteVCF(w io.Writer, sample string, tes []TileEdits, ref *genome.Sequence, tiles []*tileset.Tile) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "##fileformat=VCFv4.2")
+	fmt.Fprintln(bw, "##source=lightning")
+	fmt.Fprintln(bw, `##INFO=<ID=TILE,Number=1,Type=Integer,Description="Index of tile">`)
+	fmt.Fprintln(bw, `##INFO=<ID=TYPE,Number=1,Type=String,Description="Type of edit">`)
+	fmt.Fprintln(bw, `##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">`)
+	fmt.Fprintf(bw, "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\t%s\n", sample)
+	for _, te := range tes {
+		if te.Ref < 0 || te.Ref >= ref.Length() || te.Ref >= len(tiles) {
+			return fmt.Errorf("align: tile %d: reference block %d out of range", te.Tile, te.Ref)
+		}
+		data, t := ref.Blocks[te.Ref].Data, tiles[te.Ref]
+		for _, e := range te.Edits {
+			pos, r, a := t.Start+int64(e.Offset)+1, string(e.Ref), string(e.Alt)
+			if e.Type == ET_INS || e.Type == ET_DEL {
+				switch end := e.Offset + len(e.Ref); {
+				case e.Offset > 0 && e.Offset <= len(data):
+					anchor := string(data[e.Offset-1])
+					pos, r, a = pos-1, anchor+r, anchor+a
+				case end < len(data):
+					anchor := string(data[end])
+					r, a = r+anchor, a+anchor
+				default:
+					return fmt.Errorf("align: tile %d: no anchor base for %s at %d", te.Tile, e.Type, e.Offset)
+				}
+			}
+			fmt.Fprintf(bw, "%s\t%d\t.\t%s\t%s\t.\tPASS\tTILE=%d;TYPE=%s\tGT\t1\n",
+				t.Chr, pos, r, a, te.Tile, e.Type)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,70 @@
+package align
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func TestWriteEdits(t *testing.T) {
+	Convey("Write and read edits of tiles", t, func() {
+		tes := []TileEdits{
+			{Tile: 1, Ref: 1, Edits: []Edit{
+				{ET_SNV, 0, []byte("A"), []byte("T")},
+				{ET_INS, 3, []byte{}, []byte("GG")},
+			}},
+			{Tile: 4, Ref: 3, Edits: []Edit{{ET_DEL, 2, []byte("CA"), []byte{}}}},
+		}
+		var buf bytes.Buffer
+		So(WriteEdits(&buf, tes), ShouldBeNil)
+		So(buf.String(), ShouldEqual, editsHeader+"\n"+
+			"1\t1\t0\tsnv\tA\tT\n1\t1\t3\tins\t-\tGG\n4\t3\t2\tdel\tCA\t-\n")
+
+		tes2, err := ReadEdits(&buf)
+		So(err, ShouldBeNil)
+		So(tes2, ShouldResemble, tes)
+
+		_, err = ReadEdits(strings.NewReader(editsHeader + "\n1\t1\t0\tsv\tA\tT\n"))
+		So(err, ShouldNotBeNil)
+		_, err = ReadEdits(strings.NewReader("tile\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestWriteVCF(t *testing.T) {
+	Convey("Write edits of tiles in VCF format", t, func() {
+		ref := &genome.Sequence{Blocks: []*genome.Block{
+			{Valid: true, Data: []byte("ACGTACGT")},
+			{Valid: true, Data: []byte("TTGCA")},
+		}}
+		tiles := []*tileset.Tile{
+			{Chr: "chr1", Start: 100, End: 108},
+			{Chr: "chr1", Start: 108, End: 113},
+		}
+		tes := []TileEdits{
+			{Tile: 0, Ref: 0, Edits: []Edit{
+				{ET_SNV, 2, []byte("G"), []byte("C")},
+				{ET_DEL, 4, []byte("AC"), []byte{}},
+			}},
+			{Tile: 1, Ref: 1, Edits: []Edit{{ET_INS, 0, []byte{}, []byte("G")}}},
+		}
+
+		var buf bytes.Buffer
+		So(WriteVCF(&buf, "s1", tes, ref, tiles), ShouldBeNil)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		So(lines[5], ShouldEqual, "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\ts1")
+		So(lines[6:], ShouldResemble, []string{
+			"chr1\t103\t.\tG\tC\t.\tPASS\tTILE=0;TYPE=snv\tGT\t1",
+			"chr1\t104\t.\tTAC\tT\t.\tPASS\tTILE=0;TYPE=del\tGT\t1",
+			"chr1\t109\t.\tT\tGT\t.\tPASS\tTILE=1;TYPE=ins\tGT\t1",
+		})
+
+		tes[0].Ref = 2
+		So(WriteVCF(&buf, "s1", tes, ref, tiles), ShouldNotBeNil)
+	})
+}
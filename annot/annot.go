@@ -0,0 +1,115 @@
+// Package annot handles gene annotations in GTF, GFF3 and BED format,
+// and joins features with tiles by chromosome interval.
+package annot
+
+import (
+	"sort"
+
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/interval"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Feature represents a feature of annotation, e.g. gene, exon or regulatory region.
+type Feature struct {
+	Chr        string
+	Start, End int64 // Index, start from 0, end is exclusive.
+	Type       string
+	Name       string // Gene name.
+	ID         string
+	Strand     byte // '+', '-' or '.'.
+	Attrs      map[string]string
+}
+
+// Annotation represents features that are indexed by chromosome interval.
+type Annotation struct {
+	Features []*Feature
+	index    *interval.Index
+}
+
+// New returns a new annotation of features, chromosome names are normalized
+// so that "1" and "chr1" are the same.
+func New(features []*Feature) *Annotation {
+	a := &Annotation{Features: features, index: interval.NewIndex()}
+	for _, f := range features {
+		a.index.Add(cytomap.NormalizeChr(f.Chr), f.Start, f.End)
+	}
+	a.index.Build()
+	return a
+}
+
+// Overlap returns features that overlap range [start, end) in order of start,
+// typ filters features by type and is ignored when empty.
+func (a *Annotation) Overlap(chr string, start, end int64, typ string) []*Feature {
+	var fs []*Feature
+	for _, id := range a.index.Overlap(cytomap.NormalizeChr(chr), start, end) {
+		if f := a.Features[id]; len(typ) == 0 || f.Type == typ {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// Find returns features by gene name or ID in order,
+// typ filters features by type and is ignored when empty.
+func (a *Annotation) Find(name, typ string) []*Feature {
+	var fs []*Feature
+	for _, f := range a.Features {
+		if (f.Name == name || f.ID == name) && (len(typ) == 0 || f.Type == typ) {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// Genes returns sorted unique gene names of features that overlap range [start, end).
+func (a *Annotation) Genes(chr string, start, end int64) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, f := range a.Overlap(chr, start, end, "") {
+		if len(f.Name) > 0 && !seen[f.Name] {
+			seen[f.Name] = true
+			names = append(names, f.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TileIndex represents tiles that are indexed by chromosome interval.
+type TileIndex struct {
+	Tiles []*tileset.Tile
+	index *interval.Index
+}
+
+// NewTileIndex returns a new index of tiles, chromosome names are normalized
+// like New.
+func NewTileIndex(tiles []*tileset.Tile) *TileIndex {
+	ti := &TileIndex{Tiles: tiles, index: interval.NewIndex()}
+	for _, t := range tiles {
+		ti.index.Add(cytomap.NormalizeChr(t.Chr), t.Start, t.End)
+	}
+	ti.index.Build()
+	return ti
+}
+
+// Overlap returns indexes of tiles that overlap range [start, end) in order of start.
+func (ti *TileIndex) Overlap(chr string, start, end int64) []int {
+	return ti.index.Overlap(cytomap.NormalizeChr(chr), start, end)
+}
+
+// Features returns sorted unique indexes of tiles that overlap any of features.
+func (ti *TileIndex) Features(fs []*Feature) []int {
+	var idxes []int
+	seen := make(map[int]bool)
+	for _, f := range fs {
+		for _, i := range ti.Overlap(f.Chr, f.Start, f.End) {
+			if !seen[i] {
+				seen[i] = true
+				idxes = append(idxes, i)
+			}
+		}
+	}
+	sort.Ints(idxes)
+	return idxes
+}
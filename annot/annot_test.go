@@ -0,0 +1,56 @@
+package annot
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func TestAnnotation(t *testing.T) {
+	Convey("Query features and join with tiles", t, func() {
+		a := New([]*Feature{
+			{Chr: "chr17", Start: 100, End: 500, Type: "gene", Name: "BRCA1"},
+			{Chr: "chr17", Start: 100, End: 150, Type: "exon", Name: "BRCA1"},
+			{Chr: "chr17", Start: 300, End: 350, Type: "exon", Name: "BRCA1"},
+			{Chr: "chr17", Start: 450, End: 900, Type: "gene", Name: "NBR2"},
+			{Chr: "chr13", Start: 0, End: 100, Type: "gene", Name: "BRCA2", ID: "ENSG02"},
+		})
+
+		So(len(a.Overlap("chr17", 120, 310, "")), ShouldEqual, 3)
+		So(len(a.Overlap("chr17", 120, 310, "exon")), ShouldEqual, 2)
+		So(a.Genes("chr17", 400, 460), ShouldResemble, []string{"BRCA1", "NBR2"})
+		So(a.Genes("chr1", 400, 460), ShouldBeNil)
+		So(len(a.Find("BRCA1", "exon")), ShouldEqual, 2)
+		So(len(a.Find("ENSG02", "")), ShouldEqual, 1)
+
+		ti := NewTileIndex([]*tileset.Tile{
+			{Chr: "chr17", Start: 0, End: 124},
+			{Chr: "chr17", Start: 100, End: 224},
+			{Chr: "chr17", Start: 200, End: 324},
+			{Chr: "chr17", Start: 300, End: 424},
+			{Chr: "chr13", Start: 0, End: 124},
+		})
+		So(ti.Overlap("chr17", 150, 210), ShouldResemble, []int{1, 2})
+		So(ti.Features(a.Find("BRCA1", "exon")), ShouldResemble, []int{0, 1, 2, 3})
+		So(ti.Features(a.Find("BRCA2", "")), ShouldResemble, []int{4})
+
+		Convey("Mixed chromosome naming", func() {
+			a := New([]*Feature{
+				{Chr: "17", Start: 100, End: 500, Type: "gene", Name: "BRCA1"},
+				{Chr: "MT", Start: 0, End: 50, Type: "gene", Name: "MT-ND1"},
+			})
+			So(a.Genes("chr17", 400, 460), ShouldResemble, []string{"BRCA1"})
+			So(a.Genes("17", 400, 460), ShouldResemble, []string{"BRCA1"})
+			So(a.Genes("chrM", 10, 20), ShouldResemble, []string{"MT-ND1"})
+
+			ti := NewTileIndex([]*tileset.Tile{
+				{Chr: "chr17", Start: 0, End: 124},
+				{Chr: "chrM", Start: 0, End: 124},
+			})
+			So(ti.Overlap("17", 0, 10), ShouldResemble, []int{0})
+			So(ti.Features(a.Find("MT-ND1", "")), ShouldResemble, []int{1})
+		})
+	})
+}
@@ -0,0 +1,237 @@
+package annot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// newScanner returns scanner that handles long lines.
+func newScanner(r io.Reader) *bufio.Scanner {
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<24)
+	return snr
+}
+
+// parseColumns parses the first 8 columns of GTF and GFF3 line,
+// positions are converted to index start from 0.
+func parseColumns(infos []string, line int) (*Feature, error) {
+	start, err := strconv.ParseInt(infos[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("annot: line %d: %v", line, err)
+	}
+	end, err := strconv.ParseInt(infos[4], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("annot: line %d: %v", line, err)
+	}
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("annot: line %d: invalid range: %d-%d", line, start, end)
+	}
+	f := &Feature{
+		Chr:    infos[0],
+		Start:  start - 1,
+		End:    end,
+		Type:   infos[2],
+		Strand: '.',
+		Attrs:  make(map[string]string),
+	}
+	if len(infos[6]) == 1 {
+		f.Strand = infos[6][0]
+	}
+	return f, nil
+}
+
+// ReadGTF reads features in GTF format, gene name is from attribute "gene_name"
+// or "gene_id", and ID is from "gene_id".
+func ReadGTF(r io.Reader) (*Annotation, error) {
+	var fs []*Feature
+	snr := newScanner(r)
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		infos := strings.Split(text, "\t")
+		if len(infos) != 9 {
+			return nil, fmt.Errorf("annot: line %d: expect 9 columns but got %d", line, len(infos))
+		}
+		f, err := parseColumns(infos, line)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attr := range strings.Split(infos[8], ";") {
+			attr = strings.TrimSpace(attr)
+			if len(attr) == 0 {
+				continue
+			}
+			kv := strings.SplitN(attr, " ", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("annot: line %d: invalid attribute: %s", line, attr)
+			}
+			f.Attrs[kv[0]] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		}
+		f.ID = f.Attrs["gene_id"]
+		if f.Name = f.Attrs["gene_name"]; len(f.Name) == 0 {
+			f.Name = f.ID
+		}
+		fs = append(fs, f)
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+	return New(fs), nil
+}
+
+// ReadGFF3 reads features in GFF3 format, ID is from attribute "ID" and
+// gene name is from "Name" or "gene_name", features without name take the
+// name of parent.
+func ReadGFF3(r io.Reader) (*Annotation, error) {
+	var fs []*Feature
+	snr := newScanner(r)
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if text == "##FASTA" {
+			break
+		} else if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		infos := strings.Split(text, "\t")
+		if len(infos) != 9 {
+			return nil, fmt.Errorf("annot: line %d: expect 9 columns but got %d", line, len(infos))
+		}
+		f, err := parseColumns(infos, line)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attr := range strings.Split(infos[8], ";") {
+			if len(attr) == 0 || attr == "." {
+				continue
+			}
+			kv := strings.SplitN(attr, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("annot: line %d: invalid attribute: %s", line, attr)
+			}
+			if f.Attrs[kv[0]], err = url.PathUnescape(kv[1]); err != nil {
+				return nil, fmt.Errorf("annot: line %d: %v", line, err)
+			}
+		}
+		f.ID = f.Attrs["ID"]
+		if f.Name = f.Attrs["Name"]; len(f.Name) == 0 {
+			f.Name = f.Attrs["gene_name"]
+		}
+		fs = append(fs, f)
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+
+	// Resolve names by parents, e.g. exon -> transcript -> gene.
+	ids := make(map[string]*Feature, len(fs))
+	for _, f := range fs {
+		if len(f.ID) > 0 {
+			ids[f.ID] = f
+		}
+	}
+	var resolve func(f *Feature, depth int) string
+	resolve = func(f *Feature, depth int) string {
+		if len(f.Name) > 0 || depth > 8 {
+			return f.Name
+		}
+		// Multiple parents are separated by commas, the first one is used.
+		parent := strings.Split(f.Attrs["Parent"], ",")[0]
+		if p, ok := ids[parent]; ok && p != f {
+			f.Name = resolve(p, depth+1)
+		}
+		return f.Name
+	}
+	for _, f := range fs {
+		resolve(f, 0)
+	}
+	return New(fs), nil
+}
+
+// ReadBED reads regions in BED format as features of type "region",
+// gene name is from name column and strand is from strand column if present.
+func ReadBED(r io.Reader) (*Annotation, error) {
+	var fs []*Feature
+	snr := newScanner(r)
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if len(text) == 0 || text[0] == '#' ||
+			strings.HasPrefix(text, "track") || strings.HasPrefix(text, "browser") {
+			continue
+		}
+		infos := strings.Fields(text)
+		if len(infos) < 3 {
+			return nil, fmt.Errorf("annot: line %d: expect at least 3 columns but got %d", line, len(infos))
+		}
+		f := &Feature{Chr: infos[0], Type: "region", Strand: '.'}
+		var err error
+		if f.Start, err = strconv.ParseInt(infos[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("annot: line %d: %v", line, err)
+		}
+		if f.End, err = strconv.ParseInt(infos[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("annot: line %d: %v", line, err)
+		}
+		if f.Start < 0 || f.End < f.Start {
+			return nil, fmt.Errorf("annot: line %d: invalid range: %d-%d", line, f.Start, f.End)
+		}
+		if len(infos) > 3 && infos[3] != "." {
+			f.Name = infos[3]
+		}
+		if len(infos) > 5 && len(infos[5]) == 1 {
+			f.Strand = infos[5][0]
+		}
+		fs = append(fs, f)
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+	return New(fs), nil
+}
+
+// Load loads annotation file by extension, ".gtf" for GTF, ".gff" or ".gff3"
+// for GFF3 and ".bed" for BED, files with extra ".gz" are gzip compressed.
+func Load(name string) (*Annotation, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".gz" {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		defer gr.Close()
+		r = gr
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(name, filepath.Ext(name))))
+	}
+
+	var a *Annotation
+	switch ext {
+	case ".gtf":
+		a, err = ReadGTF(r)
+	case ".gff", ".gff3":
+		a, err = ReadGFF3(r)
+	case ".bed":
+		a, err = ReadBED(r)
+	default:
+		return nil, fmt.Errorf("annot: unknown annotation format: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return a, nil
+}
@@ -0,0 +1,103 @@
+package annot
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const gtfData = `#!genome-build GRCh37
+17	ensembl	gene	101	500	.	-	.	gene_id "ENSG01"; gene_name "BRCA1";
+17	ensembl	exon	101	150	.	-	.	gene_id "ENSG01"; transcript_id "ENST01"; gene_name "BRCA1";
+17	ensembl	gene	451	900	.	+	.	gene_id "ENSG03";
+`
+
+const gff3Data = `##gff-version 3
+chr17	.	gene	101	500	.	-	.	ID=gene:ENSG01;Name=BRCA1
+chr17	.	mRNA	101	500	.	-	.	ID=transcript:ENST01;Parent=gene:ENSG01
+chr17	.	exon	101	150	.	-	.	Parent=transcript:ENST01;note=a%3Bb
+##FASTA
+>chr17
+ACGT
+`
+
+const bedData = `track name=regions
+chr17	100	150	promoter	0	+
+chr17	300	350
+`
+
+func TestRead(t *testing.T) {
+	Convey("Read GTF format", t, func() {
+		a, err := ReadGTF(strings.NewReader(gtfData))
+		So(err, ShouldBeNil)
+		So(len(a.Features), ShouldEqual, 3)
+		f := a.Features[1]
+		So(f.Chr, ShouldEqual, "17")
+		So(f.Start, ShouldEqual, 100)
+		So(f.End, ShouldEqual, 150)
+		So(f.Type, ShouldEqual, "exon")
+		So(f.Name, ShouldEqual, "BRCA1")
+		So(f.Strand, ShouldEqual, '-')
+		So(f.Attrs["transcript_id"], ShouldEqual, "ENST01")
+		So(a.Features[2].Name, ShouldEqual, "ENSG03")
+
+		_, err = ReadGTF(strings.NewReader("17\tensembl\tgene\t0\t500\t.\t-\t.\t\n"))
+		So(err, ShouldNotBeNil)
+		_, err = ReadGTF(strings.NewReader("17\tensembl\tgene\n"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Read GFF3 format", t, func() {
+		a, err := ReadGFF3(strings.NewReader(gff3Data))
+		So(err, ShouldBeNil)
+		So(len(a.Features), ShouldEqual, 3)
+		So(a.Features[0].ID, ShouldEqual, "gene:ENSG01")
+		So(a.Features[2].Name, ShouldEqual, "BRCA1")
+		So(a.Features[2].Attrs["note"], ShouldEqual, "a;b")
+		So(a.Genes("chr17", 120, 130), ShouldResemble, []string{"BRCA1"})
+	})
+
+	Convey("Read BED format", t, func() {
+		a, err := ReadBED(strings.NewReader(bedData))
+		So(err, ShouldBeNil)
+		So(len(a.Features), ShouldEqual, 2)
+		So(*a.Features[0], ShouldResemble, Feature{Chr: "chr17", Start: 100, End: 150,
+			Type: "region", Name: "promoter", Strand: '+'})
+		So(a.Features[1].Name, ShouldEqual, "")
+
+		_, err = ReadBED(strings.NewReader("chr17\t100\n"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Load annotation file by extension", t, func() {
+		dir, err := os.MkdirTemp("", "annot")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		name := filepath.Join(dir, "genes.gtf.gz")
+		f, err := os.Create(name)
+		So(err, ShouldBeNil)
+		gw := gzip.NewWriter(f)
+		gw.Write([]byte(gtfData))
+		gw.Close()
+		f.Close()
+		a, err := Load(name)
+		So(err, ShouldBeNil)
+		So(len(a.Features), ShouldEqual, 3)
+
+		name = filepath.Join(dir, "regions.bed")
+		So(os.WriteFile(name, []byte(bedData), 0644), ShouldBeNil)
+		a, err = Load(name)
+		So(err, ShouldBeNil)
+		So(len(a.Features), ShouldEqual, 2)
+
+		name = filepath.Join(dir, "regions.txt")
+		So(os.WriteFile(name, []byte(bedData), 0644), ShouldBeNil)
+		_, err = Load(name)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,204 @@
+// Package assoc is for case/control association testing of tiles.
+package assoc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/genomelightning/lightning/annot"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Method represents statistical test method.
+type Method int
+
+const (
+	CHI_SQUARE Method = iota
+	FISHER_EXACT
+)
+
+// Options represents options of association testing.
+type Options struct {
+	Method Method
+	// Genotype tests on combine index of tiles instead of variant presence,
+	// only works with CHI_SQUARE.
+	Genotype bool
+	// MaxUnknown is the maximum fraction of DT_UNKNOWN calls of a tile.
+	MaxUnknown float64
+}
+
+// Result represents test result of a tile.
+type Result struct {
+	Tile       int
+	Chr        string
+	Start, End int64
+	Band       string
+	Genes      []string
+	Stat       float64
+	PValue     float64
+	Unknown    int // Number of DT_UNKNOWN calls.
+	Skipped    bool
+}
+
+// Report represents results of all tested or skipped tiles in order.
+type Report struct {
+	Results []*Result
+}
+
+// Skipped returns results of tiles that are skipped for too many unknown calls.
+func (r *Report) Skipped() []*Result {
+	var rs []*Result
+	for _, res := range r.Results {
+		if res.Skipped {
+			rs = append(rs, res)
+		}
+	}
+	return rs
+}
+
+// call represents genotype of a sample at a tile.
+type call struct {
+	sample   int
+	dt       bits.DiffType
+	genotype int
+}
+
+// category returns column of contingency table for the call.
+func (c call) category(genotype bool) int {
+	if !genotype {
+		return 1
+	}
+	return 1 + c.genotype
+}
+
+// Test runs association test for every tile that has non-default calls,
+// cases marks samples that are cases and others are controls.
+func Test(seqs []bits.Bitmap, cases []bool, opt Options) (*Report, error) {
+	if len(seqs) != len(cases) {
+		return nil, fmt.Errorf("assoc: %d labels for %d samples", len(cases), len(seqs))
+	}
+	if opt.Genotype && opt.Method == FISHER_EXACT {
+		return nil, errors.New("assoc: fisher exact test only works with variant presence")
+	}
+
+	calls := make(map[int][]call)
+	for i, s := range seqs {
+		if s.Len() != seqs[0].Len() {
+			return nil, bits.ErrLengthMismatch
+		}
+		sample := i
+		s.ForEachNonDefault(func(j uint64, dt bits.DiffType, combine int) {
+			calls[int(j)] = append(calls[int(j)], call{sample, dt, combine})
+		})
+	}
+
+	var nCases int
+	for _, isCase := range cases {
+		if isCase {
+			nCases++
+		}
+	}
+
+	tiles := make([]int, 0, len(calls))
+	for tile := range calls {
+		tiles = append(tiles, tile)
+	}
+	sort.Ints(tiles)
+
+	r := &Report{Results: make([]*Result, 0, len(tiles))}
+	for _, tile := range tiles {
+		res := &Result{Tile: tile}
+		r.Results = append(r.Results, res)
+
+		// Row 0 is cases and row 1 is controls, column 0 is reference.
+		table := [2][]int{{nCases}, {len(seqs) - nCases}}
+		for _, c := range calls[tile] {
+			row := 1
+			if cases[c.sample] {
+				row = 0
+			}
+			table[row][0]--
+			if c.dt == bits.DT_UNKNOWN {
+				res.Unknown++
+				continue
+			}
+			col := c.category(opt.Genotype)
+			for len(table[row]) <= col {
+				table[row] = append(table[row], 0)
+			}
+			table[row][col]++
+		}
+
+		if float64(res.Unknown) > opt.MaxUnknown*float64(len(seqs)) {
+			res.Skipped = true
+			continue
+		}
+
+		switch opt.Method {
+		case FISHER_EXACT:
+			res.PValue = FisherExact(sum(table[0][1:]), table[0][0],
+				sum(table[1][1:]), table[1][0])
+		default:
+			res.Stat, res.PValue = ChiSquare(table[:])
+		}
+	}
+	return r, nil
+}
+
+func sum(ns []int) (n int) {
+	for _, x := range ns {
+		n += x
+	}
+	return n
+}
+
+// Annotate sets coordinates and cytoband of results by tiles in the same
+// order of bit sequences, cm can be nil when no cytoband is needed.
+func (r *Report) Annotate(tiles []*tileset.Tile, cm *cytomap.CytoMap) {
+	for _, res := range r.Results {
+		if res.Tile >= len(tiles) {
+			continue
+		}
+		t := tiles[res.Tile]
+		res.Chr, res.Start, res.End = t.Chr, t.Start, t.End
+		if cm == nil {
+			continue
+		}
+		if rule := cm.Find(t.Chr, t.Start, t.End); rule != nil {
+			res.Band = rule.Section
+		}
+	}
+}
+
+// AnnotateGenes sets names of genes that overlap tiles of results by annotation,
+// coordinates of results have to be set by Annotate first.
+func (r *Report) AnnotateGenes(a *annot.Annotation) {
+	for _, res := range r.Results {
+		if len(res.Chr) > 0 {
+			res.Genes = a.Genes(res.Chr, res.Start, res.End)
+		}
+	}
+}
+
+// WriteTSV writes results in tab-separated format, genes are separated by commas,
+// skipped tiles have "NA" as statistic and p-value.
+func (r *Report) WriteTSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("tile\tchr\tstart\tend\tband\tgenes\tunknown\tstat\tpvalue\n")
+	for _, res := range r.Results {
+		fmt.Fprintf(bw, "%d\t%s\t%d\t%d\t%s\t%s\t%d\t", res.Tile, res.Chr,
+			res.Start, res.End, res.Band, strings.Join(res.Genes, ","), res.Unknown)
+		if res.Skipped {
+			bw.WriteString("NA\tNA\n")
+			continue
+		}
+		fmt.Fprintf(bw, "%.6g\t%.6g\n", res.Stat, res.PValue)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,95 @@
+package assoc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/annot"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func bitmaps(seqs []*bits.Sequence) []bits.Bitmap {
+	bms := make([]bits.Bitmap, len(seqs))
+	for i := range seqs {
+		bms[i] = seqs[i]
+	}
+	return bms
+}
+
+func TestStats(t *testing.T) {
+	Convey("Compute p-values of statistical tests", t, func() {
+		stat, p := ChiSquare([][]int{{10, 20}, {30, 40}})
+		So(stat, ShouldAlmostEqual, 0.7937, 0.0001)
+		So(p, ShouldAlmostEqual, 0.3730, 0.0001)
+
+		_, p = ChiSquare([][]int{{10, 0}, {30, 0}})
+		So(p, ShouldEqual, 1)
+
+		So(ChiSquareSF(3.841459, 1), ShouldAlmostEqual, 0.05, 0.00001)
+		So(ChiSquareSF(30, 4), ShouldAlmostEqual, 4.894e-6, 0.001e-6)
+
+		So(FisherExact(3, 1, 1, 3), ShouldAlmostEqual, 0.4857, 0.0001)
+		So(FisherExact(10, 0, 0, 10), ShouldAlmostEqual, 1.0825e-5, 0.0001e-5)
+	})
+}
+
+func TestTest(t *testing.T) {
+	Convey("Run association test of cases and controls", t, func() {
+		seqs := make([]*bits.Sequence, 20)
+		cases := make([]bool, 20)
+		for i := range seqs {
+			seqs[i] = bits.New(10)
+			cases[i] = i < 10
+			if i < 9 {
+				seqs[i].Set(2, bits.DT_SIMPLE, 1, 2)
+			}
+			if i%2 == 0 {
+				seqs[i].Set(4, bits.DT_SIMPLE, 2, 2)
+			}
+			if i < 15 {
+				seqs[i].Set(6, bits.DT_UNKNOWN, 0, 0)
+			}
+		}
+		tiles := make([]*tileset.Tile, 10)
+		for i := range tiles {
+			tiles[i] = &tileset.Tile{Chr: "chr1", Start: int64(i * 100), End: int64(i*100 + 100)}
+		}
+
+		r, err := Test(bitmaps(seqs), cases, Options{Method: FISHER_EXACT, MaxUnknown: 0.5})
+		So(err, ShouldBeNil)
+		So(len(r.Results), ShouldEqual, 3)
+		So(r.Results[0].PValue, ShouldBeLessThan, 1e-3)
+		So(r.Results[1].PValue, ShouldAlmostEqual, 1)
+		So(len(r.Skipped()), ShouldEqual, 1)
+		So(r.Skipped()[0].Tile, ShouldEqual, 6)
+
+		r.Annotate(tiles, nil)
+		var buf bytes.Buffer
+		So(r.WriteTSV(&buf), ShouldBeNil)
+		lines := strings.Split(buf.String(), "\n")
+		So(lines[1], ShouldStartWith, "2\tchr1\t200\t300\t\t\t0\t0\t")
+		So(lines[3], ShouldEqual, "6\tchr1\t600\t700\t\t\t15\tNA\tNA")
+
+		r.AnnotateGenes(annot.New([]*annot.Feature{
+			{Chr: "chr1", Start: 150, End: 250, Type: "gene", Name: "GENE2"},
+			{Chr: "chr1", Start: 0, End: 1000, Type: "gene", Name: "GENE1"},
+		}))
+		buf.Reset()
+		So(r.WriteTSV(&buf), ShouldBeNil)
+		lines = strings.Split(buf.String(), "\n")
+		So(lines[1], ShouldStartWith, "2\tchr1\t200\t300\t\tGENE1,GENE2\t0\t0\t")
+
+		r, err = Test(bitmaps(seqs), cases, Options{Genotype: true, MaxUnknown: 1})
+		So(err, ShouldBeNil)
+		So(len(r.Skipped()), ShouldEqual, 0)
+
+		_, err = Test(bitmaps(seqs), cases, Options{Method: FISHER_EXACT, Genotype: true})
+		So(err, ShouldNotBeNil)
+		_, err = Test(bitmaps(seqs), cases[1:], Options{})
+		So(err, ShouldNotBeNil)
+	})
+}
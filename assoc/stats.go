@@ -0,0 +1,147 @@
+package assoc
+
+import (
+	"math"
+)
+
+// ChiSquare computes Pearson's chi-square statistic and p-value of
+// contingency table, rows or columns whose sums are zero are ignored.
+func ChiSquare(table [][]int) (stat, p float64) {
+	rows := make([]float64, len(table))
+	var cols []float64
+	var total float64
+	for i, row := range table {
+		if len(row) > len(cols) {
+			cols = append(cols, make([]float64, len(row)-len(cols))...)
+		}
+		for j, n := range row {
+			rows[i] += float64(n)
+			cols[j] += float64(n)
+			total += float64(n)
+		}
+	}
+
+	var r, c int
+	for _, n := range rows {
+		if n > 0 {
+			r++
+		}
+	}
+	for _, n := range cols {
+		if n > 0 {
+			c++
+		}
+	}
+	if r < 2 || c < 2 {
+		return 0, 1
+	}
+
+	for i, row := range table {
+		for j := range cols {
+			if rows[i] == 0 || cols[j] == 0 {
+				continue
+			}
+			var n float64
+			if j < len(row) {
+				n = float64(row[j])
+			}
+			e := rows[i] * cols[j] / total
+			stat += (n - e) * (n - e) / e
+		}
+	}
+	return stat, ChiSquareSF(stat, float64((r-1)*(c-1)))
+}
+
+// ChiSquareSF returns survival function of chi-square distribution.
+func ChiSquareSF(x, df float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return gammaQ(df/2, x/2)
+}
+
+// gammaQ returns regularized upper incomplete gamma function Q(a, x).
+func gammaQ(a, x float64) float64 {
+	if x < a+1 {
+		return 1 - gammaPSeries(a, x)
+	}
+	return gammaQFraction(a, x)
+}
+
+func gammaPSeries(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	sum := 1 / a
+	del := sum
+	for n := 1; n < 1000; n++ {
+		del *= x / (a + float64(n))
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*1e-15 {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+func gammaQFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	lg, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < 1e-15 {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
+
+func logFactorial(n int) float64 {
+	lg, _ := math.Lgamma(float64(n + 1))
+	return lg
+}
+
+// FisherExact computes two-sided p-value of Fisher's exact test of 2x2 table:
+//
+//	a b
+//	c d
+func FisherExact(a, b, c, d int) float64 {
+	r1, r2, c1 := a+b, c+d, a+c
+	n := r1 + r2
+	base := logFactorial(r1) + logFactorial(r2) + logFactorial(c1) +
+		logFactorial(n-c1) - logFactorial(n)
+	prob := func(x int) float64 {
+		return math.Exp(base - logFactorial(x) - logFactorial(r1-x) -
+			logFactorial(c1-x) - logFactorial(r2-c1+x))
+	}
+
+	lo, hi := c1-r2, r1
+	if lo < 0 {
+		lo = 0
+	}
+	if c1 < hi {
+		hi = c1
+	}
+	observed := prob(a)
+	var p float64
+	for x := lo; x <= hi; x++ {
+		if px := prob(x); px <= observed*(1+1e-7) {
+			p += px
+		}
+	}
+	return math.Min(p, 1)
+}
@@ -0,0 +1,37 @@
+package bits
+
+import (
+	"bufio"
+	"io"
+)
+
+// Bitmap is the read interface of bit sequences of differences,
+// so analysis does not depend on how sequences are stored.
+type Bitmap interface {
+	// Len returns the number of tiles.
+	Len() uint32
+	// Get returns DiffType of tile i.
+	Get(i uint64) DiffType
+	// GetCombine returns index in CombinationTable of tile i.
+	GetCombine(i uint64) int
+	// ForEachNonDefault calls fn for every tile that is not DT_DEFAULT in order.
+	ForEachNonDefault(fn func(i uint64, dt DiffType, combine int))
+}
+
+var (
+	_ Bitmap = (*Sequence)(nil)
+	_ Bitmap = (*Compressed)(nil)
+)
+
+// ReadBitmap reads either dense or compressed sequence in binary format.
+func ReadBitmap(r io.Reader) (Bitmap, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	if string(head) == string(compressedMagic[:]) {
+		return ReadCompressed(br)
+	}
+	return Read(br)
+}
@@ -1,8 +1,9 @@
 package bits
 
 import (
-	//"fmt"
+	"fmt"
 	"math"
+	"strconv"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -170,17 +171,155 @@ func TestDumpWordsAsType(t *testing.T) {
 		bs.Set(8, DT_UNKNOWN, 0, 0)
 		bs.Set(10, DT_COMPLEX, 1, 2)
 		So(bs.DumpWordsAsType(), ShouldEqual,
-			"00000100302000000000000000000000\n")
+			"0000010030\n")
 	})
 }
 
 func TestDumpCombinesAsType(t *testing.T) {
-	Convey("Convert bit sequence combine indexes to string format(decimal form)", t, func() {
+	Convey("Convert bit sequence combine indexes to string format(hex form)", t, func() {
 		bs := New(10)
 		bs.Set(5, DT_SIMPLE, 1, 2)
 		bs.Set(8, DT_UNKNOWN, 0, 0)
 		bs.Set(10, DT_COMPLEX, 1, 2)
 		So(bs.DumpCombinesAsType(), ShouldEqual,
-			"0000020000200000\n")
+			"0000020000\n")
+
+		bs = New(20)
+		bs.Set(1, DT_COMPLEX, 4, 5)
+		bs.Set(17, DT_SIMPLE, 1, 4)
+		So(bs.DumpCombinesAsType(), ShouldEqual,
+			"0f00000000000000\n0a00\n")
+	})
+}
+
+func TestForEachNonDefault(t *testing.T) {
+	Convey("Iterate over tiles that are not default", t, func() {
+		bs := New(100)
+		bs.Set(5, DT_SIMPLE, 1, 2)
+		bs.Set(40, DT_UNKNOWN, 0, 0)
+		bs.Set(99, DT_COMPLEX, 2, 1)
+		var idxs []uint64
+		var dts []DiffType
+		var combines []int
+		bs.ForEachNonDefault(func(i uint64, dt DiffType, combine int) {
+			idxs = append(idxs, i)
+			dts = append(dts, dt)
+			combines = append(combines, combine)
+		})
+		So(idxs, ShouldResemble, []uint64{5, 40, 99})
+		So(dts, ShouldResemble, []DiffType{DT_SIMPLE, DT_UNKNOWN, DT_COMPLEX})
+		So(combines, ShouldResemble, []int{2, 0, 3})
 	})
 }
+
+func TestSetRange(t *testing.T) {
+	Convey("Set tiles in range to same value", t, func() {
+		bs := New(100)
+		So(bs.SetRange(3, 90, DT_SIMPLE, 1, 2), ShouldBeNil)
+		for i := uint64(0); i < 100; i++ {
+			if i >= 3 && i < 90 {
+				So(bs.Get(i), ShouldEqual, DT_SIMPLE)
+				So(bs.GetCombine(i), ShouldEqual, 2)
+			} else {
+				So(bs.Get(i), ShouldEqual, DT_DEFAULT)
+				So(bs.GetCombine(i), ShouldEqual, 0)
+			}
+		}
+		So(bs.SetRange(50, 50, DT_UNKNOWN, 0, 0), ShouldBeNil)
+		So(bs.Get(50), ShouldEqual, DT_SIMPLE)
+		So(bs.SetRange(50, 101, DT_UNKNOWN, 0, 0), ShouldEqual, ErrOutOfRange)
+		So(bs.SetRange(0, 1, DiffType(4), 0, 0), ShouldEqual, ErrOutOfRange)
+	})
+}
+
+func TestSetWords(t *testing.T) {
+	Convey("Set tiles by packed words", t, func() {
+		src := randomSequence(80, 3)
+		bs := New(100)
+		So(bs.SetWords(7, 80, src.words, src.combines), ShouldBeNil)
+		So(sameTiles(bs, 7, src, 80), ShouldBeTrue)
+		So(bs.Get(6), ShouldEqual, DT_DEFAULT)
+		So(bs.Get(87), ShouldEqual, DT_DEFAULT)
+		So(bs.SetWords(30, 80, src.words, src.combines), ShouldEqual, ErrOutOfRange)
+		So(bs.SetWords(0, 90, src.words, src.combines), ShouldEqual, ErrOutOfRange)
+	})
+}
+
+// legacySet is the formatting-based implementation of Set for benchmarks.
+func legacySet(s *Sequence, i uint64, dt DiffType, num1, num2 int) {
+	s.setType(i, dt)
+	i *= 4
+	index := i >> log2WordSize
+	baseSize := i & (wordSize - 1)
+	combineIndex := fmt.Sprintf("%04s", strconv.FormatInt(int64(GetCombineTableIndex(num1, num2)), 2))
+	var j uint64 = 0
+	for ; j < 4; j++ {
+		if combineIndex[3-j] > 48 {
+			s.combines[index] |= 1 << (baseSize + j)
+		} else {
+			s.combines[index] &^= 1 << (baseSize + j)
+		}
+	}
+}
+
+// legacyGetCombine is the math.Pow-based implementation of GetCombine for benchmarks.
+func legacyGetCombine(s *Sequence, i uint64) int {
+	i *= 4
+	index := i >> log2WordSize
+	baseSize := i & (wordSize - 1)
+	num := 0
+	var j uint64 = 0
+	for ; j < 4; j++ {
+		if s.combines[index]&(1<<(baseSize+j)) != 0 {
+			num += int(math.Pow(2, float64(j)))
+		}
+	}
+	return num
+}
+
+const benchLength = 1 << 20
+
+func BenchmarkSet(b *testing.B) {
+	bs := New(benchLength)
+	for i := 0; i < b.N; i++ {
+		bs.Set(uint64(i&(benchLength-1)), DT_SIMPLE, 4, 5)
+	}
+}
+
+func BenchmarkSetLegacy(b *testing.B) {
+	bs := New(benchLength)
+	for i := 0; i < b.N; i++ {
+		legacySet(bs, uint64(i&(benchLength-1)), DT_SIMPLE, 4, 5)
+	}
+}
+
+func BenchmarkGetCombine(b *testing.B) {
+	bs := randomSequence(1<<12, 4)
+	for i := 0; i < b.N; i++ {
+		bs.GetCombine(uint64(i & (1<<12 - 1)))
+	}
+}
+
+func BenchmarkGetCombineLegacy(b *testing.B) {
+	bs := randomSequence(1<<12, 4)
+	for i := 0; i < b.N; i++ {
+		legacyGetCombine(bs, uint64(i&(1<<12-1)))
+	}
+}
+
+func BenchmarkSetRange(b *testing.B) {
+	bs := New(benchLength)
+	b.SetBytes(benchLength)
+	for i := 0; i < b.N; i++ {
+		bs.SetRange(0, benchLength, DT_SIMPLE, 4, 5)
+	}
+}
+
+func BenchmarkSetWords(b *testing.B) {
+	src := randomSequence(1<<12, 5)
+	bs := New(benchLength)
+	b.SetBytes(1 << 12)
+	for i := 0; i < b.N; i++ {
+		bs.SetWords(uint64(i&255)*(1<<12)+7, 1<<12-7, src.words, src.combines)
+	}
+}
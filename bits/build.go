@@ -0,0 +1,142 @@
+package bits
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tile represents DiffType and pair of numbers of a tile.
+type Tile struct {
+	DT   DiffType
+	Nums [2]int
+}
+
+// Builder builds sequence by appending tiles in order,
+// tiles are packed into words directly.
+type Builder struct {
+	length   uint64
+	words    []uint64
+	combines []uint64
+	word     uint64 // Word that is being packed.
+	combine  uint64 // Combine word that is being packed.
+}
+
+// NewBuilder initializes a new builder with capacity of tiles.
+func NewBuilder(capacity uint32) *Builder {
+	return &Builder{
+		words:    make([]uint64, 0, wordsNeeded(capacity, 2)),
+		combines: make([]uint64, 0, wordsNeeded(capacity, 4)),
+	}
+}
+
+// Add appends a tile to the sequence.
+func (b *Builder) Add(dt DiffType, num1, num2 int) error {
+	if dt < DT_DEFAULT || dt > DT_UNKNOWN {
+		return fmt.Errorf("bits: invalid DiffType: %d", dt)
+	}
+	if b.length >= math.MaxUint32 {
+		return ErrTooLong
+	}
+
+	b.word |= cells[dt] << (b.length & 31 * 2)
+	b.combine |= uint64(GetCombineTableIndex(num1, num2)) << (b.length & 15 * 4)
+	b.length++
+	if b.length&15 == 0 {
+		b.combines = append(b.combines, b.combine)
+		b.combine = 0
+	}
+	if b.length&31 == 0 {
+		b.words = append(b.words, b.word)
+		b.word = 0
+	}
+	return nil
+}
+
+// Len returns the number of tiles that have been added.
+func (b *Builder) Len() uint32 {
+	return uint32(b.length)
+}
+
+// Sequence returns the sequence of tiles that have been added,
+// the builder should not be used afterwards.
+func (b *Builder) Sequence() *Sequence {
+	if b.length&31 != 0 {
+		b.words = append(b.words, b.word)
+	}
+	if b.length&15 != 0 {
+		b.combines = append(b.combines, b.combine)
+	}
+	s := &Sequence{length: uint32(b.length), words: b.words, combines: b.combines}
+	for uint64(len(s.words)) < wordsNeeded(s.length, 2) {
+		s.words = append(s.words, 0)
+	}
+	for uint64(len(s.combines)) < wordsNeeded(s.length, 4) {
+		s.combines = append(s.combines, 0)
+	}
+	return s
+}
+
+// Build builds sequence from DiffTypes and pairs of numbers of tiles,
+// nums can be nil when all combinations are unknown.
+func Build(dts []DiffType, nums [][2]int) (*Sequence, error) {
+	if nums != nil && len(nums) != len(dts) {
+		return nil, fmt.Errorf("bits: %d pairs for %d tiles", len(nums), len(dts))
+	}
+	if uint64(len(dts)) > math.MaxUint32 {
+		return nil, ErrTooLong
+	}
+
+	b := NewBuilder(uint32(len(dts)))
+	var pair [2]int
+	for i, dt := range dts {
+		if nums != nil {
+			pair = nums[i]
+		}
+		if err := b.Add(dt, pair[0], pair[1]); err != nil {
+			return nil, err
+		}
+	}
+	return b.Sequence(), nil
+}
+
+// BuildFromChan builds sequence from tiles that are received from channel
+// until it is closed.
+func BuildFromChan(tiles <-chan Tile) (*Sequence, error) {
+	b := NewBuilder(0)
+	for t := range tiles {
+		if err := b.Add(t.DT, t.Nums[0], t.Nums[1]); err != nil {
+			return nil, err
+		}
+	}
+	return b.Sequence(), nil
+}
+
+// GetRange decodes tiles start from index start into dts and combines,
+// the number of tiles is len(dts), combines can be nil or must have same length.
+func (s *Sequence) GetRange(start uint64, dts []DiffType, combines []int) error {
+	n := uint64(len(dts))
+	if start+n > uint64(s.length) || (combines != nil && len(combines) != len(dts)) {
+		return ErrOutOfRange
+	}
+
+	for i := uint64(0); i < n; {
+		w := getBits(s.words, (start+i)*2)
+		for j := 0; j < 32 && i < n; j++ {
+			dts[i] = DiffType(cells[w&3])
+			w >>= 2
+			i++
+		}
+	}
+	if combines == nil {
+		return nil
+	}
+	for i := uint64(0); i < n; {
+		w := getBits(s.combines, (start+i)*4)
+		for j := 0; j < 16 && i < n; j++ {
+			combines[i] = int(w & 0xf)
+			w >>= 4
+			i++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package bits
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuild(t *testing.T) {
+	Convey("Build bit sequence from slices of tiles", t, func() {
+		for _, n := range []int{0, 1, 16, 31, 32, 33, 100} {
+			src := randomSequence(uint32(n), int64(n))
+			dts := make([]DiffType, n)
+			nums := make([][2]int, n)
+			for i := range dts {
+				dts[i] = src.Get(uint64(i))
+				nums[i] = CombinationTable[src.GetCombine(uint64(i))].Nums
+			}
+
+			bs, err := Build(dts, nums)
+			So(err, ShouldBeNil)
+			So(bs, ShouldResemble, src)
+		}
+
+		bs, err := Build([]DiffType{DT_SIMPLE, DT_UNKNOWN}, nil)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "13\n")
+		So(bs.DumpCombinesAsType(), ShouldEqual, "00\n")
+
+		_, err = Build([]DiffType{DT_SIMPLE}, [][2]int{})
+		So(err, ShouldNotBeNil)
+		_, err = Build([]DiffType{DiffType(7)}, nil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Build bit sequence from channel of tiles", t, func() {
+		src := randomSequence(70, 7)
+		tiles := make(chan Tile)
+		go func() {
+			for i := uint64(0); i < 70; i++ {
+				tiles <- Tile{src.Get(i), CombinationTable[src.GetCombine(i)].Nums}
+			}
+			close(tiles)
+		}()
+		bs, err := BuildFromChan(tiles)
+		So(err, ShouldBeNil)
+		So(bs, ShouldResemble, src)
+	})
+}
+
+func TestGetRange(t *testing.T) {
+	Convey("Decode tiles in range", t, func() {
+		src := randomSequence(100, 8)
+		dts := make([]DiffType, 60)
+		combines := make([]int, 60)
+		So(src.GetRange(35, dts, combines), ShouldBeNil)
+		for i := range dts {
+			So(dts[i], ShouldEqual, src.Get(uint64(35+i)))
+			So(combines[i], ShouldEqual, src.GetCombine(uint64(35+i)))
+		}
+		So(src.GetRange(35, dts, nil), ShouldBeNil)
+		So(src.GetRange(41, dts, nil), ShouldEqual, ErrOutOfRange)
+		So(src.GetRange(0, dts, combines[1:]), ShouldEqual, ErrOutOfRange)
+	})
+}
+
+func BenchmarkBuild(b *testing.B) {
+	dts := make([]DiffType, benchLength)
+	nums := make([][2]int, benchLength)
+	for i := range dts {
+		dts[i] = DiffType(i & 3)
+		nums[i] = [2]int{1, 2}
+	}
+	b.SetBytes(benchLength)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Build(dts, nums)
+	}
+}
+
+func BenchmarkGetRange(b *testing.B) {
+	bs := randomSequence(1<<12, 9)
+	dts := make([]DiffType, 1<<12)
+	combines := make([]int, 1<<12)
+	b.SetBytes(1 << 12)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bs.GetRange(0, dts, combines)
+	}
+}
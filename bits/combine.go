@@ -29,14 +29,33 @@ var CombinationTable = [16]Combination{
 // Length to be 16 is because it can be represented by 4 bits.
 const CombineTableLength = 16
 
+// combineNum is the upper bound(exclusive) of numbers in CombinationTable,
+// and combineIndexes is the reverse lookup table of it by num1*combineNum+num2.
+var (
+	combineNum     int
+	combineIndexes []uint8
+)
+
+func init() {
+	for i := 1; i < CombineTableLength; i++ {
+		for _, num := range CombinationTable[i].Nums {
+			if num >= combineNum {
+				combineNum = num + 1
+			}
+		}
+	}
+	combineIndexes = make([]uint8, combineNum*combineNum)
+	for i := 1; i < CombineTableLength; i++ {
+		nums := CombinationTable[i].Nums
+		combineIndexes[nums[0]*combineNum+nums[1]] = uint8(i)
+	}
+}
+
 // GetCombineTableIndex returns index of combinations in CombinationTable,
 // and it returns 0 when it does not match any.
 func GetCombineTableIndex(num1, num2 int) int {
-	for i := 1; i < CombineTableLength; i++ {
-		if num1 == CombinationTable[i].Nums[0] &&
-			num2 == CombinationTable[i].Nums[1] {
-			return i
-		}
+	if num1 < 0 || num1 >= combineNum || num2 < 0 || num2 >= combineNum {
+		return 0
 	}
-	return 0
+	return int(combineIndexes[num1*combineNum+num2])
 }
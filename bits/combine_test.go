@@ -7,7 +7,14 @@ import (
 )
 
 func Test_init(t *testing.T) {
-
+	Convey("Build reverse lookup table of CombinationTable", t, func() {
+		for i := 1; i < CombineTableLength; i++ {
+			nums := CombinationTable[i].Nums
+			So(combineIndexes[nums[0]*combineNum+nums[1]], ShouldEqual, i)
+		}
+		So(combineNum, ShouldEqual, 7)
+		So(combineIndexes[0], ShouldEqual, 0)
+	})
 }
 
 func TestGetCombineTableIndex(t *testing.T) {
@@ -18,6 +25,15 @@ func TestGetCombineTableIndex(t *testing.T) {
 
 		Convey("Combination that does not exist", func() {
 			So(GetCombineTableIndex(3, 4), ShouldEqual, 0)
+			So(GetCombineTableIndex(-1, 2), ShouldEqual, 0)
+			So(GetCombineTableIndex(1, 100), ShouldEqual, 0)
+			So(GetCombineTableIndex(6, 7), ShouldEqual, 0)
 		})
 	})
 }
+
+func BenchmarkGetCombineTableIndex(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GetCombineTableIndex(4, 5)
+	}
+}
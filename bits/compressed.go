@@ -0,0 +1,183 @@
+package bits
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// compressedMagic is the file header of serialized compressed sequence.
+var compressedMagic = [4]byte{'L', 'B', 'S', 'C'}
+
+// run represents continuous tiles that have same DiffType and combine index.
+type run struct {
+	start, length uint32
+	dt            DiffType
+	combine       uint8
+}
+
+// Compressed represents run-length encoded bit sequence, only tiles that are not
+// DT_DEFAULT with combine index 0 are stored, so the size is close to the number
+// of non-default tiles. It is read-only.
+type Compressed struct {
+	length uint32
+	runs   []run // Sorted by start and do not overlap.
+}
+
+// Compress converts dense bit sequence to compressed form.
+func Compress(s *Sequence) *Compressed {
+	c := &Compressed{length: s.length}
+	l := wordsNeeded(s.length, 2)
+	var last *run
+	var i uint64 = 0
+	for ; i < l; i++ {
+		if s.words[i] == 0 && s.combines[i*2] == 0 &&
+			(i*2+1 >= uint64(len(s.combines)) || s.combines[i*2+1] == 0) {
+			continue
+		}
+
+		for j := i * 32; j < (i+1)*32 && j < uint64(s.length); j++ {
+			dt, combine := s.Get(j), uint8(s.GetCombine(j))
+			if dt == DT_DEFAULT && combine == 0 {
+				continue
+			}
+			if last != nil && last.dt == dt && last.combine == combine &&
+				uint64(last.start+last.length) == j {
+				last.length++
+				continue
+			}
+			c.runs = append(c.runs, run{uint32(j), 1, dt, combine})
+			last = &c.runs[len(c.runs)-1]
+		}
+	}
+	return c
+}
+
+// Dense converts compressed sequence back to dense form.
+func (c *Compressed) Dense() *Sequence {
+	s := New(c.length)
+	for _, r := range c.runs {
+		nums := CombinationTable[r.combine].Nums
+		for j := r.start; j < r.start+r.length; j++ {
+			s.Set(uint64(j), r.dt, nums[0], nums[1])
+		}
+	}
+	return s
+}
+
+// Len returns the number of tiles in the sequence.
+func (c *Compressed) Len() uint32 {
+	return c.length
+}
+
+// find returns the run that contains tile i, or nil when it is default.
+func (c *Compressed) find(i uint64) *run {
+	k := sort.Search(len(c.runs), func(k int) bool {
+		return uint64(c.runs[k].start+c.runs[k].length) > i
+	})
+	if k < len(c.runs) && uint64(c.runs[k].start) <= i {
+		return &c.runs[k]
+	}
+	return nil
+}
+
+// Get returns tile value by given index.
+func (c *Compressed) Get(i uint64) DiffType {
+	if r := c.find(i); r != nil {
+		return r.dt
+	}
+	return DT_DEFAULT
+}
+
+// GetCombine returns index in CombinationTable by given index of tile.
+func (c *Compressed) GetCombine(i uint64) int {
+	if r := c.find(i); r != nil {
+		return int(r.combine)
+	}
+	return 0
+}
+
+// ForEachNonDefault calls fn for every tile that is not DT_DEFAULT in order.
+func (c *Compressed) ForEachNonDefault(fn func(i uint64, dt DiffType, combine int)) {
+	for _, r := range c.runs {
+		if r.dt == DT_DEFAULT {
+			continue
+		}
+		for j := r.start; j < r.start+r.length; j++ {
+			fn(uint64(j), r.dt, int(r.combine))
+		}
+	}
+}
+
+// WriteTo writes compressed sequence in binary format:
+//
+//	magic(4 bytes) length(uint32) runs(uvarint) [gap(uvarint) length(uvarint) cell(byte)]...
+//
+// Gap is the number of default tiles before the run, cell is DiffType<<4 | combine index.
+func (c *Compressed) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	// A run takes two uvarints of 32-bit values and the cell.
+	buf := make([]byte, 2*binary.MaxVarintLen32+1)
+	bw.Write(compressedMagic[:])
+	binary.LittleEndian.PutUint32(buf, c.length)
+	bw.Write(buf[:4])
+	n := int64(8)
+
+	k := binary.PutUvarint(buf, uint64(len(c.runs)))
+	bw.Write(buf[:k])
+	n += int64(k)
+	var end uint32
+	for _, r := range c.runs {
+		k = binary.PutUvarint(buf, uint64(r.start-end))
+		k += binary.PutUvarint(buf[k:], uint64(r.length))
+		buf[k] = byte(r.dt)<<4 | r.combine
+		bw.Write(buf[:k+1])
+		n += int64(k + 1)
+		end = r.start + r.length
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ReadCompressed reads compressed sequence in binary format that is written by WriteTo.
+func ReadCompressed(r io.Reader) (*Compressed, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, err
+	}
+	if string(buf[:4]) != string(compressedMagic[:]) {
+		return nil, ErrInvalidFormat
+	}
+
+	c := &Compressed{length: binary.LittleEndian.Uint32(buf[4:])}
+	num, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	var end uint64
+	for ; num > 0; num-- {
+		gap, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		cell, err := br.ReadByte()
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		start := end + gap
+		end = start + length
+		if end > uint64(c.length) || length == 0 || DiffType(cell>>4) > DT_UNKNOWN {
+			return nil, ErrInvalidFormat
+		}
+		c.runs = append(c.runs, run{uint32(start), uint32(length), DiffType(cell >> 4), cell & 0xf})
+	}
+	return c, nil
+}
@@ -0,0 +1,83 @@
+package bits
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompress(t *testing.T) {
+	Convey("Convert bit sequence between dense and compressed form", t, func() {
+		bs := New(100000)
+		bs.Set(5, DT_SIMPLE, 1, 2)
+		for i := uint64(60); i < 200; i++ {
+			bs.Set(i, DT_UNKNOWN, 0, 0)
+		}
+		bs.Set(70000, DT_COMPLEX, 2, 1)
+		bs.Set(70001, DT_DEFAULT, 1, 1)
+		bs.Set(99999, DT_SIMPLE, 4, 5)
+
+		c := Compress(bs)
+		So(c.Len(), ShouldEqual, 100000)
+		So(len(c.runs), ShouldEqual, 5)
+		So(c.Get(5), ShouldEqual, DT_SIMPLE)
+		So(c.GetCombine(5), ShouldEqual, 2)
+		So(c.Get(100), ShouldEqual, DT_UNKNOWN)
+		So(c.Get(200), ShouldEqual, DT_DEFAULT)
+		So(c.GetCombine(70001), ShouldEqual, 1)
+		So(c.Get(99999), ShouldEqual, DT_SIMPLE)
+		So(c.Dense(), ShouldResemble, bs)
+
+		var idxs []uint64
+		c.ForEachNonDefault(func(i uint64, dt DiffType, combine int) {
+			idxs = append(idxs, i)
+		})
+		So(len(idxs), ShouldEqual, 143)
+
+		var buf bytes.Buffer
+		n, err := c.WriteTo(&buf)
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, buf.Len())
+		So(n, ShouldBeLessThan, 30)
+
+		c2, err := ReadCompressed(&buf)
+		So(err, ShouldBeNil)
+		So(c2, ShouldResemble, c)
+
+		_, err = ReadCompressed(bytes.NewReader([]byte("LBSC\x10\x00\x00\x00\x01\x0f\x02\x10")))
+		So(err, ShouldEqual, ErrInvalidFormat)
+
+		// Gaps and lengths of runs take 5 bytes each.
+		c = &Compressed{length: 1<<32 - 1, runs: []run{
+			{start: 1 << 29, length: 1 << 29, dt: DT_UNKNOWN},
+			{start: 1<<31 + 1<<29, length: 1 << 30, dt: DT_SIMPLE, combine: 3},
+		}}
+		buf.Reset()
+		_, err = c.WriteTo(&buf)
+		So(err, ShouldBeNil)
+		c2, err = ReadCompressed(&buf)
+		So(err, ShouldBeNil)
+		So(c2, ShouldResemble, c)
+	})
+}
+
+func TestReadBitmap(t *testing.T) {
+	Convey("Read bit sequence in either dense or compressed form", t, func() {
+		bs := New(100)
+		bs.Set(5, DT_SIMPLE, 1, 2)
+
+		var buf bytes.Buffer
+		bs.WriteTo(&buf)
+		b, err := ReadBitmap(&buf)
+		So(err, ShouldBeNil)
+		So(b, ShouldResemble, bs)
+
+		Compress(bs).WriteTo(&buf)
+		b, err = ReadBitmap(&buf)
+		So(err, ShouldBeNil)
+		So(b, ShouldResemble, Compress(bs))
+		So(DumpWordsAsBits(b), ShouldEqual, bs.DumpWordsAsBits())
+		So(DumpCombinesAsBits(b), ShouldEqual, bs.DumpCombinesAsBits())
+	})
+}
@@ -0,0 +1,68 @@
+package bits
+
+import (
+	"errors"
+	mathbits "math/bits"
+)
+
+// lowMask marks the low bit of every 2-bit cell in a word.
+const lowMask = uint64(0x5555555555555555)
+
+// ErrLengthMismatch is returned when two sequences do not have same length.
+var ErrLengthMismatch = errors.New("bits: sequences have different length")
+
+// unknownCells returns low bits of cells that are DT_UNKNOWN.
+func unknownCells(w uint64) uint64 {
+	return w & (w >> 1) & lowMask
+}
+
+// nonDefaultCells returns low bits of cells that are not DT_DEFAULT.
+func nonDefaultCells(w uint64) uint64 {
+	return (w | w>>1) & lowMask
+}
+
+// cellMask returns low bits of cells in given word that are within length.
+func (s *Sequence) cellMask(i uint64) uint64 {
+	n := uint64(s.length)
+	switch full := n >> 5; {
+	case i < full:
+		return lowMask
+	case i == full:
+		return lowMask & (1<<((n&31)*2) - 1)
+	}
+	return 0
+}
+
+// Distance computes tile-level Hamming distance of two bit sequences.
+// Tiles that are DT_UNKNOWN in either sequence are excluded, tiles differ
+// when DiffTypes are different, or both are Simple/Complex but have different
+// combine indexes. It returns number of different tiles and number of tiles
+// that were compared.
+func Distance(a, b *Sequence) (diff, compared uint64, err error) {
+	if a.length != b.length {
+		return 0, 0, ErrLengthMismatch
+	}
+
+	l := wordsNeeded(a.length, 2)
+	var i uint64 = 0
+	for ; i < l; i++ {
+		wa, wb := a.words[i], b.words[i]
+		known := a.cellMask(i) &^ (unknownCells(wa) | unknownCells(wb))
+		x := wa ^ wb
+		d := (x | x>>1) & known
+
+		// Same type but may be different combinations.
+		same := known &^ d & nonDefaultCells(wa)
+		for same != 0 {
+			c := uint64(mathbits.TrailingZeros64(same))
+			if a.GetCombine(i*32+c/2) != b.GetCombine(i*32+c/2) {
+				d |= 1 << c
+			}
+			same &= same - 1
+		}
+
+		diff += uint64(mathbits.OnesCount64(d))
+		compared += uint64(mathbits.OnesCount64(known))
+	}
+	return diff, compared, nil
+}
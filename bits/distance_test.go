@@ -0,0 +1,51 @@
+package bits
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDistance(t *testing.T) {
+	Convey("Compute tile-level distance of two bit sequences", t, func() {
+		Convey("Identical sequences", func() {
+			a, b := New(40), New(40)
+			a.Set(3, DT_SIMPLE, 1, 2)
+			b.Set(3, DT_SIMPLE, 1, 2)
+			diff, compared, err := Distance(a, b)
+			So(err, ShouldBeNil)
+			So(diff, ShouldEqual, 0)
+			So(compared, ShouldEqual, 40)
+		})
+
+		Convey("Different types and combinations", func() {
+			a, b := New(70), New(70)
+			a.Set(1, DT_SIMPLE, 1, 2)
+			a.Set(33, DT_COMPLEX, 1, 2)
+			b.Set(33, DT_SIMPLE, 1, 2)
+			a.Set(65, DT_SIMPLE, 1, 2)
+			b.Set(65, DT_SIMPLE, 2, 2)
+			diff, compared, err := Distance(a, b)
+			So(err, ShouldBeNil)
+			So(diff, ShouldEqual, 3)
+			So(compared, ShouldEqual, 70)
+		})
+
+		Convey("Unknown tiles are excluded", func() {
+			a, b := New(10), New(10)
+			a.Set(2, DT_UNKNOWN, 0, 0)
+			b.Set(4, DT_UNKNOWN, 0, 0)
+			a.Set(4, DT_SIMPLE, 1, 2)
+			b.Set(6, DT_COMPLEX, 1, 2)
+			diff, compared, err := Distance(a, b)
+			So(err, ShouldBeNil)
+			So(diff, ShouldEqual, 1)
+			So(compared, ShouldEqual, 8)
+		})
+
+		Convey("Sequences with different length", func() {
+			_, _, err := Distance(New(10), New(11))
+			So(err, ShouldEqual, ErrLengthMismatch)
+		})
+	})
+}
@@ -0,0 +1,229 @@
+package bits
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// cellBits is the bits form of DiffTypes, low bit first.
+var cellBits = [4]string{"00", "01", "10", "11"}
+
+// DumpWordsAsBits converts tile values to string format(bits form),
+// a line for every 32 tiles and low bit first:
+//
+//	00 - Default
+//	01 - Simple
+//	10 - Complex
+//	11 - Unknown
+func DumpWordsAsBits(b Bitmap) string {
+	buf := bytes.NewBufferString("")
+	l := wordsNeeded(b.Len(), 2)
+	var i uint64 = 0
+	for ; i < l; i++ {
+		var j uint64 = 0
+		for ; j < 32; j++ {
+			buf.WriteString(cellBits[b.Get(i*32+j)])
+		}
+		buf.WriteString("\n")
+	}
+	return string(buf.Bytes())
+}
+
+// DumpCombinesAsBits converts combine indexes to string format(bits form),
+// a line for every 16 tiles and low bit first.
+func DumpCombinesAsBits(b Bitmap) string {
+	buf := bytes.NewBufferString("")
+	l := wordsNeeded(b.Len(), 4)
+	var i uint64 = 0
+	for ; i < l; i++ {
+		var j uint64 = 0
+		for ; j < 16; j++ {
+			combine := b.GetCombine(i*16 + j)
+			for k := uint(0); k < 4; k++ {
+				buf.WriteByte(byte('0' + combine>>k&1))
+			}
+		}
+		buf.WriteString("\n")
+	}
+	return string(buf.Bytes())
+}
+
+// DumpWordsAsType converts tile values to string format(DiffType form),
+// a line for every 32 tiles:
+//
+//	0 - Default
+//	1 - Simple
+//	2 - Complex
+//	3 - Unknown
+func DumpWordsAsType(b Bitmap) string {
+	buf := bytes.NewBufferString("")
+	WriteTypes(buf, b, 32)
+	return string(buf.Bytes())
+}
+
+// DumpCombinesAsType converts combine indexes to string format(hex form),
+// a line for every 16 tiles.
+func DumpCombinesAsType(b Bitmap) string {
+	buf := bytes.NewBufferString("")
+	WriteCombines(buf, b, 16, CF_HEX)
+	return string(buf.Bytes())
+}
+
+// CombineFormat represents text format of combine indexes.
+type CombineFormat int
+
+const (
+	CF_HEX  CombineFormat = iota // A hex digit of index, e.g. "2".
+	CF_PAIR                      // Pair of numbers in CombinationTable, e.g. "{1,2}".
+)
+
+// DumpOptions represents options of dumping bit sequences in text format.
+type DumpOptions struct {
+	Width   int // Number of tiles per row, default is 32.
+	Combine CombineFormat
+}
+
+// writeRows writes tiles of bitmap in rows of width by fn, it stops at the length.
+func writeRows(w io.Writer, b Bitmap, width int, fn func(buf *bytes.Buffer, start, end uint64)) error {
+	if width <= 0 {
+		width = 32
+	}
+	buf := bytes.NewBufferString("")
+	l := uint64(b.Len())
+	for start := uint64(0); start < l; start += uint64(width) {
+		end := start + uint64(width)
+		if end > l {
+			end = l
+		}
+		buf.Reset()
+		fn(buf, start, end)
+		buf.WriteString("\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+func writeTypes(buf *bytes.Buffer, b Bitmap, start, end uint64) {
+	for i := start; i < end; i++ {
+		buf.WriteByte(byte('0' + b.Get(i)))
+	}
+}
+
+func writeCombines(buf *bytes.Buffer, b Bitmap, start, end uint64, format CombineFormat) {
+	for i := start; i < end; i++ {
+		combine := b.GetCombine(i)
+		if format != CF_PAIR {
+			buf.WriteByte(hexDigits[combine])
+			continue
+		}
+		if i > start {
+			buf.WriteByte(' ')
+		}
+		nums := CombinationTable[combine].Nums
+		fmt.Fprintf(buf, "{%d,%d}", nums[0], nums[1])
+	}
+}
+
+// WriteTypes writes DiffTypes of tiles as digits in rows of width.
+func WriteTypes(w io.Writer, b Bitmap, width int) error {
+	return writeRows(w, b, width, func(buf *bytes.Buffer, start, end uint64) {
+		writeTypes(buf, b, start, end)
+	})
+}
+
+// WriteCombines writes combine indexes of tiles in rows of width,
+// pairs are separated by spaces.
+func WriteCombines(w io.Writer, b Bitmap, width int, format CombineFormat) error {
+	return writeRows(w, b, width, func(buf *bytes.Buffer, start, end uint64) {
+		writeCombines(buf, b, start, end, format)
+	})
+}
+
+// Dump writes tiles in text format, every row has DiffTypes of tiles,
+// a tab and combine indexes of same tiles, e.g. with width 10:
+//
+//	0100300000	0200000000
+//
+// Output can be parsed back by ParseDump.
+func Dump(w io.Writer, b Bitmap, opt DumpOptions) error {
+	return writeRows(w, b, opt.Width, func(buf *bytes.Buffer, start, end uint64) {
+		writeTypes(buf, b, start, end)
+		buf.WriteByte('\t')
+		writeCombines(buf, b, start, end, opt.Combine)
+	})
+}
+
+// ParseDump parses text format that is written by Dump back to sequence,
+// rows can have any width and combine indexes can be in any format.
+func ParseDump(r io.Reader) (*Sequence, error) {
+	type cell struct {
+		dt      DiffType
+		combine int
+	}
+	var cells []cell
+
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<30)
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if len(text) == 0 {
+			continue
+		}
+		infos := strings.Split(text, "\t")
+		if len(infos) != 2 {
+			return nil, fmt.Errorf("bits: line %d: need types and combines separated by tab", line)
+		}
+
+		var combines []int
+		if strings.Contains(infos[1], "{") {
+			for _, pair := range strings.Fields(infos[1]) {
+				var num1, num2 int
+				if _, err := fmt.Sscanf(pair, "{%d,%d}", &num1, &num2); err != nil {
+					return nil, fmt.Errorf("bits: line %d: invalid pair %q", line, pair)
+				}
+				combines = append(combines, GetCombineTableIndex(num1, num2))
+			}
+		} else {
+			for _, c := range infos[1] {
+				idx := strings.IndexRune(hexDigits, unicode.ToLower(c))
+				if idx < 0 {
+					return nil, fmt.Errorf("bits: line %d: invalid combine index %q", line, c)
+				}
+				combines = append(combines, idx)
+			}
+		}
+
+		if len(combines) != len(infos[0]) {
+			return nil, fmt.Errorf("bits: line %d: %d types but %d combines",
+				line, len(infos[0]), len(combines))
+		}
+		for i, c := range infos[0] {
+			if c < '0' || c > '3' {
+				return nil, fmt.Errorf("bits: line %d: invalid type %q", line, c)
+			}
+			cells = append(cells, cell{DiffType(c - '0'), combines[i]})
+		}
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+	if uint64(len(cells)) > math.MaxUint32 {
+		return nil, ErrTooLong
+	}
+
+	s := New(uint32(len(cells)))
+	for i, c := range cells {
+		nums := CombinationTable[c.combine].Nums
+		s.Set(uint64(i), c.dt, nums[0], nums[1])
+	}
+	return s, nil
+}
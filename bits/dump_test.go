@@ -0,0 +1,55 @@
+package bits
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDump(t *testing.T) {
+	Convey("Dump bit sequence in text format and parse it back", t, func() {
+		bs := New(12)
+		bs.Set(1, DT_SIMPLE, 1, 2)
+		bs.Set(4, DT_UNKNOWN, 0, 0)
+		bs.Set(10, DT_COMPLEX, 4, 5)
+
+		Convey("Combine indexes in hex form", func() {
+			var buf bytes.Buffer
+			So(Dump(&buf, bs, DumpOptions{Width: 5}), ShouldBeNil)
+			So(buf.String(), ShouldEqual, "01003\t02000\n00000\t00000\n20\tf0\n")
+
+			bs2, err := ParseDump(&buf)
+			So(err, ShouldBeNil)
+			So(bs2, ShouldResemble, bs)
+		})
+
+		Convey("Combine indexes in pair form", func() {
+			var buf bytes.Buffer
+			So(Dump(&buf, bs, DumpOptions{Width: 8, Combine: CF_PAIR}), ShouldBeNil)
+			So(buf.String(), ShouldStartWith, "01003000\t{0,0} {1,2} {0,0}")
+			So(buf.String(), ShouldEndWith, "\n0020\t{0,0} {0,0} {4,5} {0,0}\n")
+
+			bs2, err := ParseDump(&buf)
+			So(err, ShouldBeNil)
+			So(bs2, ShouldResemble, bs)
+		})
+
+		Convey("Write types and combines separately", func() {
+			var buf bytes.Buffer
+			So(WriteTypes(&buf, bs, 0), ShouldBeNil)
+			So(buf.String(), ShouldEqual, "010030000020\n")
+			buf.Reset()
+			So(WriteCombines(&buf, bs, 6, CF_HEX), ShouldBeNil)
+			So(buf.String(), ShouldEqual, "020000\n0000f0\n")
+		})
+
+		Convey("Parse invalid dumps", func() {
+			for _, dump := range []string{"0100", "0140\t0000", "010\t00", "01\t0g", "01\t{1,2} {x}"} {
+				_, err := ParseDump(strings.NewReader(dump))
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package bits
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// maskedWord returns word i of words with bits at or after nbits cleared.
+func maskedWord(words []uint64, i, nbits uint64) uint64 {
+	full, rest := nbits>>log2WordSize, nbits&(wordSize-1)
+	switch {
+	case i < full:
+		return words[i]
+	case i == full && rest > 0:
+		return words[i] & (1<<rest - 1)
+	}
+	return 0
+}
+
+// toDense converts bitmap to dense sequence, it returns s itself when
+// it is already dense.
+func toDense(b Bitmap) *Sequence {
+	switch s := b.(type) {
+	case *Sequence:
+		return s
+	case *Compressed:
+		return s.Dense()
+	}
+	bd := NewBuilder(b.Len())
+	for i := uint64(0); i < uint64(b.Len()); i++ {
+		nums := CombinationTable[b.GetCombine(i)].Nums
+		bd.Add(b.Get(i), nums[0], nums[1])
+	}
+	return bd.Sequence()
+}
+
+// Equal returns true if two bitmaps have same length and same tiles,
+// bits after the length are ignored.
+func Equal(a, b Bitmap) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	sa, sb := toDense(a), toDense(b)
+	n := uint64(sa.length)
+	for i := uint64(0); i < wordsNeeded(sa.length, 2); i++ {
+		if maskedWord(sa.words, i, n*2) != maskedWord(sb.words, i, n*2) {
+			return false
+		}
+	}
+	for i := uint64(0); i < wordsNeeded(sa.length, 4); i++ {
+		if maskedWord(sa.combines, i, n*4) != maskedWord(sb.combines, i, n*4) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns SHA-256 of tiles of bitmap, it only depends on the length and
+// tiles, so it is same for any form of storage and bits after the length.
+func Hash(b Bitmap) [sha256.Size]byte {
+	s := toDense(b)
+	n := uint64(s.length)
+	h := sha256.New()
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint32(buf, s.length)
+	h.Write(buf[:4])
+	for i := uint64(0); i < wordsNeeded(s.length, 2); i++ {
+		binary.LittleEndian.PutUint64(buf, maskedWord(s.words, i, n*2))
+		h.Write(buf)
+	}
+	for i := uint64(0); i < wordsNeeded(s.length, 4); i++ {
+		binary.LittleEndian.PutUint64(buf, maskedWord(s.combines, i, n*4))
+		h.Write(buf)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Difference represents a tile that differs between two bitmaps.
+type Difference struct {
+	Index                  uint64
+	OldType, NewType       DiffType
+	OldCombine, NewCombine int
+}
+
+// Compare returns tiles that differ between old and new bitmaps in order,
+// words that are same are skipped.
+func Compare(old, new Bitmap) ([]Difference, error) {
+	if old.Len() != new.Len() {
+		return nil, ErrLengthMismatch
+	}
+
+	so, sn := toDense(old), toDense(new)
+	n := uint64(so.length)
+	var diffs []Difference
+	for i := uint64(0); i < wordsNeeded(so.length, 2); i++ {
+		// Every word of tile values covers two words of combines.
+		if maskedWord(so.words, i, n*2) == maskedWord(sn.words, i, n*2) &&
+			maskedWord(so.combines, i*2, n*4) == maskedWord(sn.combines, i*2, n*4) &&
+			maskedWord(so.combines, i*2+1, n*4) == maskedWord(sn.combines, i*2+1, n*4) {
+			continue
+		}
+		for j := i * 32; j < (i+1)*32 && j < n; j++ {
+			d := Difference{j, so.Get(j), sn.Get(j), so.GetCombine(j), sn.GetCombine(j)}
+			if d.OldType != d.NewType || d.OldCombine != d.NewCombine {
+				diffs = append(diffs, d)
+			}
+		}
+	}
+	return diffs, nil
+}
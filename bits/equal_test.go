@@ -0,0 +1,64 @@
+package bits
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEqual(t *testing.T) {
+	Convey("Check equality and hash of bit sequences", t, func() {
+		a := randomSequence(100, 10)
+		b, _ := Concat(a)
+		So(Equal(a, b), ShouldBeTrue)
+		So(Hash(a), ShouldEqual, Hash(b))
+		So(Equal(a, Compress(a)), ShouldBeTrue)
+		So(Hash(a), ShouldEqual, Hash(Compress(a)))
+
+		Convey("Bits after the length are ignored", func() {
+			parent := randomSequence(128, 11)
+			view, _ := parent.Slice(32, 80)
+			cp, _ := Concat(view)
+			So(view.words[1], ShouldNotEqual, cp.words[1])
+			So(Equal(view, cp), ShouldBeTrue)
+			So(Hash(view), ShouldEqual, Hash(cp))
+		})
+
+		Convey("Different tiles or length", func() {
+			b.Set(99, DT_UNKNOWN, 0, 0)
+			if a.Get(99) == DT_UNKNOWN {
+				b.Set(99, DT_SIMPLE, 0, 0)
+			}
+			So(Equal(a, b), ShouldBeFalse)
+			So(Hash(a), ShouldNotEqual, Hash(b))
+
+			So(Equal(New(10), New(11)), ShouldBeFalse)
+			So(Hash(New(10)), ShouldNotEqual, Hash(New(11)))
+		})
+	})
+}
+
+func TestCompare(t *testing.T) {
+	Convey("List different tiles of two bit sequences", t, func() {
+		old, new := New(100), New(100)
+		old.Set(3, DT_SIMPLE, 1, 2)
+		new.Set(3, DT_SIMPLE, 1, 2)
+		new.Set(40, DT_COMPLEX, 2, 1)
+		old.Set(96, DT_SIMPLE, 1, 2)
+		new.Set(96, DT_SIMPLE, 2, 2)
+
+		diffs, err := Compare(old, Compress(new))
+		So(err, ShouldBeNil)
+		So(diffs, ShouldResemble, []Difference{
+			{40, DT_DEFAULT, DT_COMPLEX, 0, 3},
+			{96, DT_SIMPLE, DT_SIMPLE, 2, 4},
+		})
+
+		diffs, err = Compare(old, old)
+		So(err, ShouldBeNil)
+		So(diffs, ShouldBeEmpty)
+
+		_, err = Compare(New(1), New(2))
+		So(err, ShouldEqual, ErrLengthMismatch)
+	})
+}
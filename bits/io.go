@@ -0,0 +1,86 @@
+package bits
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// magic is the file header of serialized bit sequence.
+var magic = [4]byte{'L', 'B', 'S', 'Q'}
+
+// maxPrealloc is the maximum number of words that are allocated before they
+// are read.
+const maxPrealloc = 1 << 16
+
+// ErrInvalidFormat is returned when data is not a serialized bit sequence.
+var ErrInvalidFormat = errors.New("bits: invalid format")
+
+// WriteTo writes sequence in binary format:
+//
+//	magic(4 bytes) length(uint32) words(uint64...) combines(uint64...)
+//
+// All numbers are in little endian.
+func (s *Sequence) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 8)
+	bw.Write(magic[:])
+	binary.LittleEndian.PutUint32(buf, s.length)
+	bw.Write(buf[:4])
+	for _, words := range [][]uint64{s.words, s.combines} {
+		for _, word := range words {
+			binary.LittleEndian.PutUint64(buf, word)
+			bw.Write(buf)
+		}
+	}
+	n := int64(8 + 8*(len(s.words)+len(s.combines)))
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Read reads sequence in binary format that is written by WriteTo.
+func Read(r io.Reader) (*Sequence, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, err
+	}
+	if string(buf[:4]) != string(magic[:]) {
+		return nil, ErrInvalidFormat
+	}
+
+	length := binary.LittleEndian.Uint32(buf[4:])
+	s := &Sequence{length: length}
+	var err error
+	if s.words, err = readWords(br, wordsNeeded(length, 2)); err != nil {
+		return nil, err
+	}
+	if s.combines, err = readWords(br, wordsNeeded(length, 4)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// readWords reads n words, the slice grows as words are read so corrupt
+// length does not allocate memory beyond data.
+func readWords(r io.Reader, n uint64) ([]uint64, error) {
+	c := n
+	if c > maxPrealloc {
+		c = maxPrealloc
+	}
+	words := make([]uint64, 0, c)
+	buf := make([]byte, 8)
+	for i := uint64(0); i < n; i++ {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		words = append(words, binary.LittleEndian.Uint64(buf))
+	}
+	return words, nil
+}
@@ -0,0 +1,36 @@
+package bits
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteTo(t *testing.T) {
+	Convey("Write and read bit sequence in binary format", t, func() {
+		bs := New(100)
+		bs.Set(5, DT_SIMPLE, 1, 2)
+		bs.Set(70, DT_UNKNOWN, 0, 0)
+		bs.Set(99, DT_COMPLEX, 4, 5)
+
+		var buf bytes.Buffer
+		n, err := bs.WriteTo(&buf)
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, buf.Len())
+
+		bs2, err := Read(bytes.NewReader(buf.Bytes()))
+		So(err, ShouldBeNil)
+		So(bs2, ShouldResemble, bs)
+
+		_, err = Read(bytes.NewReader(buf.Bytes()[:20]))
+		So(err, ShouldNotBeNil)
+		_, err = Read(bytes.NewReader([]byte("not a sequence")))
+		So(err, ShouldEqual, ErrInvalidFormat)
+
+		// Huge length in header without data.
+		_, err = Read(bytes.NewReader([]byte("LBSQ\xff\xff\xff\xff")))
+		So(err, ShouldEqual, io.ErrUnexpectedEOF)
+	})
+}
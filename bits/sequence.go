@@ -2,9 +2,7 @@
 package bits
 
 import (
-	"bytes"
-	"fmt"
-	"math"
+	mathbits "math/bits"
 	"strconv"
 )
 
@@ -30,6 +28,16 @@ const (
 	DT_UNKNOWN
 )
 
+var diffTypeNames = [...]string{"default", "simple", "complex", "unknown"}
+
+// String returns name of DiffType in lower case.
+func (dt DiffType) String() string {
+	if dt < 0 || int(dt) >= len(diffTypeNames) {
+		return "DiffType(" + strconv.Itoa(int(dt)) + ")"
+	}
+	return diffTypeNames[dt]
+}
+
 // Sequence represents bit sequence that use 2 bits as a tile.
 type Sequence struct {
 	length   uint32
@@ -46,6 +54,15 @@ func New(length uint32) *Sequence {
 	}
 }
 
+// Len returns the number of tiles in the sequence.
+func (s *Sequence) Len() uint32 {
+	return s.length
+}
+
+// cells maps DiffType to value of 2-bit cell, low bit first in dumps,
+// and it also maps value of cell back to DiffType.
+var cells = [4]uint64{0, 2, 1, 3}
+
 // Set sets tile value and combination of given index according to DiffType.
 //
 // 	Default - 00
@@ -54,77 +71,93 @@ func New(length uint32) *Sequence {
 // 	Unknown - 11
 func (s *Sequence) Set(i uint64, dt DiffType, num1, num2 int) *Sequence {
 	// TODO: extend size as needed.
+	if dt >= DT_DEFAULT && dt <= DT_UNKNOWN {
+		s.setType(i, dt)
+	}
+	s.setCombine(i, GetCombineTableIndex(num1, num2))
+	return s
+}
 
+func (s *Sequence) setType(i uint64, dt DiffType) {
 	i *= 2
 	index := i >> log2WordSize
-	baseSize := i & (wordSize - 1)
-
-	switch dt {
-	case DT_DEFAULT:
-		s.words[index] &^= 1 << baseSize
-		s.words[index] &^= 1 << (baseSize + 1)
-	case DT_SIMPLE:
-		s.words[index] &^= 1 << baseSize
-		s.words[index] |= 1 << (baseSize + 1)
-	case DT_COMPLEX:
-		s.words[index] |= 1 << baseSize
-		s.words[index] &^= 1 << (baseSize + 1)
-	case DT_UNKNOWN:
-		s.words[index] |= 1 << baseSize
-		s.words[index] |= 1 << (baseSize + 1)
+	shift := i & (wordSize - 1)
+	s.words[index] = s.words[index]&^(3<<shift) | cells[dt]<<shift
+}
+
+func (s *Sequence) setCombine(i uint64, combine int) {
+	i *= 4
+	index := i >> log2WordSize
+	shift := i & (wordSize - 1)
+	s.combines[index] = s.combines[index]&^(0xf<<shift) | uint64(combine&0xf)<<shift
+}
+
+// fill returns a word that every n-bit cell is v.
+func fill(v uint64, n uint) uint64 {
+	w := v
+	for size := n; size < uint(wordSize); size *= 2 {
+		w |= w << size
 	}
-	//fmt.Printf("length in bits: %d, real size of sets: %d, bits: %d, index: %d\n", s.length, len(s.words), i, index)
+	return w
+}
 
-	i *= 2
-	index = i >> log2WordSize
-	baseSize = i & (wordSize - 1)
-	combineIndex := fmt.Sprintf("%04s", strconv.FormatInt(int64(GetCombineTableIndex(num1, num2)), 2))
-	//fmt.Printf("combine index: %s, number: %d, index: %d\n", combineIndex, i, index)
-	var j uint64 = 0
-	for ; j < 4; j++ {
-		if combineIndex[3-j] > 48 {
-			s.combines[index] |= 1 << (baseSize + j)
-		} else {
-			s.combines[index] &^= 1 << (baseSize + j)
+// SetRange sets tiles in range [start, end) to same value and combination,
+// whole words are filled at once.
+func (s *Sequence) SetRange(start, end uint64, dt DiffType, num1, num2 int) error {
+	if start > end || end > uint64(s.length) || dt < DT_DEFAULT || dt > DT_UNKNOWN {
+		return ErrOutOfRange
+	}
+	word, combine := fill(cells[dt], 2), fill(uint64(GetCombineTableIndex(num1, num2)), 4)
+	for i := start; i < end; {
+		n := end - i
+		if n > 16 {
+			n = 16
 		}
+		setBits(s.words, i*2, word, n*2)
+		setBits(s.combines, i*4, combine, n*4)
+		i += n
 	}
-	//fmt.Println(reverse(fmt.Sprintf("%064b", s.combines[index])))
-	return s
+	return nil
+}
+
+// SetWords sets n tiles start from index i by words and combines that are
+// packed in the same layout of sequence, i.e. 32 tiles per word in words and
+// 16 tiles per word in combines.
+func (s *Sequence) SetWords(i, n uint64, words, combines []uint64) error {
+	if i+n > uint64(s.length) || n*2 > uint64(len(words))*wordSize ||
+		n*4 > uint64(len(combines))*wordSize {
+		return ErrOutOfRange
+	}
+	copyBits(s.words, i*2, words, 0, n*2)
+	copyBits(s.combines, i*4, combines, 0, n*4)
+	return nil
 }
 
 // Get returns tile value by given index.
 func (s *Sequence) Get(i uint64) DiffType {
 	i *= 2
-	index := i >> log2WordSize
-	baseSize := i & (wordSize - 1)
-	low := s.words[index] & (1 << baseSize)
-	high := s.words[index] & (1 << (baseSize + 1))
-	switch {
-	case low == 0 && high != 0:
-		return DT_SIMPLE
-	case low != 0 && high == 0:
-		return DT_COMPLEX
-	case low != 0 && high != 0:
-		return DT_UNKNOWN
-	}
-	return DT_DEFAULT
+	return DiffType(cells[s.words[i>>log2WordSize]>>(i&(wordSize-1))&3])
 }
 
 // GetCombine returns index in CombinationTable by given index of tile.
 func (s *Sequence) GetCombine(i uint64) int {
 	i *= 4
-	index := i >> log2WordSize
-	baseSize := i & (wordSize - 1)
-	num := 0
+	return int(s.combines[i>>log2WordSize] >> (i & (wordSize - 1)) & 0xf)
+}
 
-	var j uint64 = 0
-	for ; j < 4; j++ {
-		if s.combines[index]&(1<<(baseSize+j)) != 0 {
-			num += int(math.Pow(2, float64(j)))
+// ForEachNonDefault calls fn for every tile that is not DT_DEFAULT in order,
+// words that only contain default tiles are skipped.
+func (s *Sequence) ForEachNonDefault(fn func(i uint64, dt DiffType, combine int)) {
+	l := wordsNeeded(s.length, 2)
+	var i uint64 = 0
+	for ; i < l; i++ {
+		cells := nonDefaultCells(s.words[i]) & s.cellMask(i)
+		for cells != 0 {
+			j := i*32 + uint64(mathbits.TrailingZeros64(cells))/2
+			fn(j, s.Get(j), s.GetCombine(j))
+			cells &= cells - 1
 		}
-		//fmt.Println(num, s.combines[index]&(1<<(baseSize+j)), int(math.Pow(2, float64(j))))
 	}
-	return num
 }
 
 func reverse(str string) string {
@@ -139,64 +172,20 @@ func reverse(str string) string {
 
 // DumpWordsAsBits converts tile values to string format(bits form):
 func (s *Sequence) DumpWordsAsBits() string {
-	buf := bytes.NewBufferString("")
-	l := wordsNeeded(s.length, 2)
-	var i uint64 = 0
-	for ; i < l; i++ {
-		buf.WriteString(reverse(fmt.Sprintf("%064b", s.words[i])))
-		buf.WriteString("\n")
-	}
-	return string(buf.Bytes())
+	return DumpWordsAsBits(s)
 }
 
 // DumpCombinesAsBits converts combine indexes to string format(bits form):
 func (s *Sequence) DumpCombinesAsBits() string {
-	buf := bytes.NewBufferString("")
-	l := wordsNeeded(s.length, 4)
-	var i uint64 = 0
-	for ; i < l; i++ {
-		buf.WriteString(reverse(fmt.Sprintf("%064b", s.combines[i])))
-		buf.WriteString("\n")
-	}
-	return string(buf.Bytes())
+	return DumpCombinesAsBits(s)
 }
 
 // DumpWordsAsType converts tile values to string format(DiffType form):
-//
-// 	0 - Default
-// 	1 - Simple
-// 	2 - Complex
-// 	3 - Unknown
 func (s *Sequence) DumpWordsAsType() string {
-	buf := bytes.NewBufferString("")
-	l := wordsNeeded(s.length, 2)
-	var i uint64 = 0
-	for ; i < l; i++ {
-		var j uint64 = 0
-		for ; j < 32; j += 1 {
-			buf.WriteString(string(s.Get(i*31+j) + 48))
-		}
-		buf.WriteString("\n")
-	}
-	return string(buf.Bytes())
+	return DumpWordsAsType(s)
 }
 
-// DumpCombinesAsType converts combine indexes to string format(decimal form):
-//
-// 	0 - Default
-// 	1 - Simple
-// 	2 - Complex
-// 	3 - Unknown
+// DumpCombinesAsType converts combine indexes to string format(hex form):
 func (s *Sequence) DumpCombinesAsType() string {
-	buf := bytes.NewBufferString("")
-	l := wordsNeeded(s.length, 4)
-	var i uint64 = 0
-	for ; i < l; i++ {
-		var j uint64 = 0
-		for ; j < 16; j += 1 {
-			buf.WriteString(string(s.GetCombine(i*15+j) + 48))
-		}
-		buf.WriteString("\n")
-	}
-	return string(buf.Bytes())
+	return DumpCombinesAsType(s)
 }
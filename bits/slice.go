@@ -0,0 +1,101 @@
+package bits
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrOutOfRange is returned when range is out of sequence.
+	ErrOutOfRange = errors.New("bits: range out of sequence")
+	// ErrTooLong is returned when result has more tiles than uint32 can hold.
+	ErrTooLong = errors.New("bits: sequence is too long")
+)
+
+// getBits returns 64 bits of words start from bit offset off,
+// bits after the end of words are zeros.
+func getBits(words []uint64, off uint64) uint64 {
+	idx, shift := off>>log2WordSize, off&(wordSize-1)
+	w := words[idx] >> shift
+	if shift > 0 && idx+1 < uint64(len(words)) {
+		w |= words[idx+1] << (wordSize - shift)
+	}
+	return w
+}
+
+// setBits sets n(<=64) bits of words start from bit offset off by low bits of w.
+func setBits(words []uint64, off uint64, w uint64, n uint64) {
+	if n == 0 {
+		return
+	}
+	mask := ^uint64(0)
+	if n < wordSize {
+		mask = 1<<n - 1
+	}
+	w &= mask
+	idx, shift := off>>log2WordSize, off&(wordSize-1)
+	words[idx] = words[idx]&^(mask<<shift) | w<<shift
+	if shift > 0 && shift+n > wordSize {
+		words[idx+1] = words[idx+1]&^(mask>>(wordSize-shift)) | w>>(wordSize-shift)
+	}
+}
+
+// copyBits copies n bits from src at offset srcOff to dst at offset dstOff.
+func copyBits(dst []uint64, dstOff uint64, src []uint64, srcOff uint64, n uint64) {
+	for n > 0 {
+		size := n
+		if size > wordSize {
+			size = wordSize
+		}
+		setBits(dst, dstOff, getBits(src, srcOff), size)
+		dstOff += size
+		srcOff += size
+		n -= size
+	}
+}
+
+// Slice returns tiles in range [start, end) as a new sequence. When start is
+// a multiple of 32, the result is a view that shares storage with s, so
+// setting tiles of one changes the other; otherwise tiles are copied.
+func (s *Sequence) Slice(start, end uint64) (*Sequence, error) {
+	if start > end || end > uint64(s.length) {
+		return nil, ErrOutOfRange
+	}
+
+	n := uint32(end - start)
+	if n == 0 {
+		return New(0), nil
+	}
+	if start&31 == 0 {
+		return &Sequence{
+			length:   n,
+			words:    s.words[start>>5 : start>>5+wordsNeeded(n, 2)],
+			combines: s.combines[start>>4 : start>>4+wordsNeeded(n, 4)],
+		}, nil
+	}
+
+	seq := New(n)
+	copyBits(seq.words, 0, s.words, start*2, uint64(n)*2)
+	copyBits(seq.combines, 0, s.combines, start*4, uint64(n)*4)
+	return seq, nil
+}
+
+// Concat concatenates sequences into a new sequence in order.
+func Concat(seqs ...*Sequence) (*Sequence, error) {
+	var total uint64
+	for _, s := range seqs {
+		total += uint64(s.length)
+	}
+	if total > math.MaxUint32 {
+		return nil, ErrTooLong
+	}
+
+	seq := New(uint32(total))
+	var off uint64
+	for _, s := range seqs {
+		copyBits(seq.words, off*2, s.words, 0, uint64(s.length)*2)
+		copyBits(seq.combines, off*4, s.combines, 0, uint64(s.length)*4)
+		off += uint64(s.length)
+	}
+	return seq, nil
+}
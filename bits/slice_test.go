@@ -0,0 +1,84 @@
+package bits
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func randomSequence(length uint32, seed int64) *Sequence {
+	rnd := rand.New(rand.NewSource(seed))
+	bs := New(length)
+	for i := uint64(0); i < uint64(length); i++ {
+		c := CombinationTable[rnd.Intn(CombineTableLength)].Nums
+		bs.Set(i, DiffType(rnd.Intn(4)), c[0], c[1])
+	}
+	return bs
+}
+
+func sameTiles(a *Sequence, aOff uint64, b *Sequence, n uint64) bool {
+	for i := uint64(0); i < n; i++ {
+		if a.Get(aOff+i) != b.Get(i) || a.GetCombine(aOff+i) != b.GetCombine(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSlice(t *testing.T) {
+	Convey("Slice bit sequence by range", t, func() {
+		bs := randomSequence(200, 1)
+
+		Convey("Word-aligned slice is a view", func() {
+			s, err := bs.Slice(64, 150)
+			So(err, ShouldBeNil)
+			So(s.Len(), ShouldEqual, 86)
+			So(sameTiles(bs, 64, s, 86), ShouldBeTrue)
+
+			s.Set(0, DT_COMPLEX, 4, 5)
+			So(bs.Get(64), ShouldEqual, DT_COMPLEX)
+			So(bs.GetCombine(64), ShouldEqual, 15)
+		})
+
+		Convey("Unaligned slice is a copy", func() {
+			for _, r := range [][2]uint64{{1, 2}, {3, 200}, {31, 33}, {17, 113}, {199, 200}} {
+				s, err := bs.Slice(r[0], r[1])
+				So(err, ShouldBeNil)
+				So(s.Len(), ShouldEqual, r[1]-r[0])
+				So(sameTiles(bs, r[0], s, r[1]-r[0]), ShouldBeTrue)
+			}
+
+			s, _ := bs.Slice(5, 10)
+			s.Set(0, DT_COMPLEX, 4, 5)
+			So(bs.GetCombine(5), ShouldNotEqual, 15)
+		})
+
+		Convey("Empty and invalid ranges", func() {
+			s, err := bs.Slice(200, 200)
+			So(err, ShouldBeNil)
+			So(s.Len(), ShouldEqual, 0)
+			_, err = bs.Slice(10, 201)
+			So(err, ShouldEqual, ErrOutOfRange)
+			_, err = bs.Slice(10, 9)
+			So(err, ShouldEqual, ErrOutOfRange)
+		})
+	})
+}
+
+func TestConcat(t *testing.T) {
+	Convey("Concatenate bit sequences", t, func() {
+		bs := randomSequence(300, 2)
+		var parts []*Sequence
+		for _, r := range [][2]uint64{{0, 7}, {7, 64}, {64, 64}, {64, 101}, {101, 300}} {
+			s, err := bs.Slice(r[0], r[1])
+			So(err, ShouldBeNil)
+			parts = append(parts, s)
+		}
+
+		seq, err := Concat(parts...)
+		So(err, ShouldBeNil)
+		So(seq.Len(), ShouldEqual, 300)
+		So(sameTiles(bs, 0, seq, 300), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/genomelightning/lightning/annot"
+	"github.com/genomelightning/lightning/assoc"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+)
+
+var assocCmd = &Command{
+	Name:  "assoc",
+	Usage: "<labels.tsv>",
+	Short: "Run case/control association test of tiles",
+	Flags: flag.NewFlagSet("assoc", flag.ContinueOnError),
+}
+
+var (
+	assocMethod     = assocCmd.Flags.String("method", "chi2", "test method: chi2 or fisher")
+	assocGenotype   = assocCmd.Flags.Bool("genotype", false, "test on combine index instead of variant presence")
+	assocMaxUnknown = assocCmd.Flags.Float64("max-unknown", 0.1, "maximum fraction of unknown calls of a tile")
+	assocTiles      = assocCmd.Flags.String("tiles", "", "comma-separated list of tileset files for coordinates")
+	assocMap        = assocCmd.Flags.String("map", "", "UCSC cytoband file for band annotation")
+	assocHg         = assocCmd.Flags.Int("hg", 19, "version of human genome assembly")
+	assocGenes      = assocCmd.Flags.String("genes", "", "GTF, GFF3 or BED file for gene annotation")
+)
+
+func init() {
+	assocCmd.Run = runAssoc
+	register(assocCmd)
+}
+
+// readLabels reads lines in format "<diff file>\t<case|control>".
+func readLabels(name string) (files []string, cases []bool, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	snr := bufio.NewScanner(f)
+	for line := 1; snr.Scan(); line++ {
+		text := strings.TrimSpace(snr.Text())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		infos := strings.Split(text, "\t")
+		if len(infos) != 2 || (infos[1] != "case" && infos[1] != "control") {
+			return nil, nil, fmt.Errorf("%s:%d: invalid label line: %s", name, line, text)
+		}
+		files = append(files, infos[0])
+		cases = append(cases, infos[1] == "case")
+	}
+	return files, cases, snr.Err()
+}
+
+func runAssoc(cmd *Command, args []string) error {
+	if err := checkFormat(*assocMethod, "chi2", "fisher"); err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("need exactly one labels file")
+	}
+	opt := assoc.Options{Genotype: *assocGenotype, MaxUnknown: *assocMaxUnknown}
+	if *assocMethod == "fisher" {
+		opt.Method = assoc.FISHER_EXACT
+	}
+
+	files, cases, err := readLabels(args[0])
+	if err != nil {
+		return err
+	}
+	seqs := make([]bits.Bitmap, len(files))
+	for i, name := range files {
+		if seqs[i], err = readDiff(name); err != nil {
+			return err
+		}
+	}
+	r, err := assoc.Test(seqs, cases, opt)
+	if err != nil {
+		return err
+	}
+
+	tiles, err := readTiles(*assocTiles)
+	if err != nil {
+		return err
+	}
+	var cm *cytomap.CytoMap
+	if len(*assocMap) > 0 {
+		if cm, err = cytomap.ParseCytoMap(*assocHg, *assocMap); err != nil {
+			return err
+		}
+	}
+	if tiles != nil {
+		r.Annotate(tiles, cm)
+	}
+	if len(*assocGenes) > 0 {
+		a, err := annot.Load(*assocGenes)
+		if err != nil {
+			return err
+		}
+		r.AnnotateGenes(a)
+	}
+	return r.WriteTSV(os.Stdout)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/genomelightning/lightning/genome"
+)
+
+var consensusCmd = &Command{
+	Name:  "consensus",
+	Usage: "<sample.genome>...",
+	Short: "Build consensus tiled genome of a cohort by most common variants",
+	Flags: flag.NewFlagSet("consensus", flag.ContinueOnError),
+}
+
+var consensusOutput = consensusCmd.Flags.String("o", "", "output file, default is standard output")
+
+func init() {
+	consensusCmd.Run = runConsensus
+	register(consensusCmd)
+}
+
+func runConsensus(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return usageError("need tiled genome files")
+	}
+	seqs := make([]*genome.Sequence, len(args))
+	for i, name := range args {
+		var err error
+		if seqs[i], err = readGenome(name); err != nil {
+			return err
+		}
+	}
+	cs, err := genome.Consensus(seqs)
+	if err != nil {
+		return err
+	}
+
+	w, err := create(*consensusOutput)
+	if err != nil {
+		return err
+	}
+	if err = cs.Write(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/cytomap"
+)
+
+var cytomapCmd = &Command{
+	Name:  "cytomap",
+	Usage: "[chr:start-end | chr:pos]...",
+	Short: "Look up cytobands of regions, or list all bands without regions",
+	Flags: flag.NewFlagSet("cytomap", flag.ContinueOnError),
+}
+
+var (
+	cytomapFile   = cytomapCmd.Flags.String("map", "", "UCSC cytoband file")
+	cytomapHg     = cytomapCmd.Flags.Int("hg", 19, "version of human genome assembly")
+	cytomapFormat = cytomapCmd.Flags.String("format", "json", "output format: json or tsv")
+)
+
+func init() {
+	cytomapCmd.Run = runCytomap
+	register(cytomapCmd)
+}
+
+// Band represents cytoband of a region.
+type Band struct {
+	Region string `json:"region,omitempty"`
+	Chr    string `json:"chr"`
+	Start  int64  `json:"start"`
+	End    int64  `json:"end"`
+	Band   string `json:"band"`
+	Stain  string `json:"stain"`
+}
+
+// parseRegion parses region in format "chr:start-end" or "chr:pos".
+func parseRegion(s string) (chr string, start, end int64, err error) {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 {
+		return "", 0, 0, usageError("invalid region: " + s)
+	}
+	chr = s[:i]
+	pos := strings.SplitN(strings.Replace(s[i+1:], ",", "", -1), "-", 2)
+	if start, err = strconv.ParseInt(pos[0], 10, 64); err != nil {
+		return "", 0, 0, usageError("invalid region: " + s)
+	}
+	end = start + 1
+	if len(pos) == 2 {
+		if end, err = strconv.ParseInt(pos[1], 10, 64); err != nil || end < start {
+			return "", 0, 0, usageError("invalid region: " + s)
+		}
+	}
+	return chr, start, end, nil
+}
+
+func runCytomap(cmd *Command, args []string) error {
+	if err := checkFormat(*cytomapFormat, "json", "tsv"); err != nil {
+		return err
+	}
+	if len(*cytomapFile) == 0 {
+		return usageError("need cytoband file")
+	}
+	cm, err := cytomap.ParseCytoMap(*cytomapHg, *cytomapFile)
+	if err != nil {
+		return err
+	}
+
+	bands := make([]*Band, 0, len(args))
+	if len(args) == 0 {
+		for _, rule := range cm.Rules {
+			bands = append(bands, &Band{"", rule.Chr, rule.Start, rule.End, rule.Section, rule.Color})
+		}
+	}
+	for _, arg := range args {
+		chr, start, end, err := parseRegion(arg)
+		if err != nil {
+			return err
+		}
+		rule := cm.Find(chr, start, end)
+		if rule == nil {
+			return fmt.Errorf("no band found for region: %s", arg)
+		}
+		bands = append(bands, &Band{arg, rule.Chr, rule.Start, rule.End, rule.Section, rule.Color})
+	}
+
+	if *cytomapFormat == "json" {
+		return writeJSON(os.Stdout, bands)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "region\tchr\tstart\tend\tband\tstain")
+	for _, b := range bands {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", b.Region, b.Chr, b.Start, b.End, b.Band, b.Stain)
+	}
+	return w.Flush()
+}
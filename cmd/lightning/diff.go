@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/genomelightning/lightning"
+	"github.com/genomelightning/lightning/align"
+	"github.com/genomelightning/lightning/genome"
+)
+
+var diffCmd = &Command{
+	Name:  "diff",
+	Usage: "<sample.genome> <reference.genome>...",
+	Short: "Compute bit sequences of differences of tiled genome against references",
+	Flags: flag.NewFlagSet("diff", flag.ContinueOnError),
+}
+
+var (
+	diffOutput = diffCmd.Flags.String("o", "",
+		"comma-separated list of output files for references in order, default is standard output for single reference")
+	diffDiploid = diffCmd.Flags.Bool("diploid", false, "sample is a diploid tiled genome, only single reference is allowed")
+	diffEdits   = diffCmd.Flags.String("edits", "",
+		"align differing blocks and write edits of simple tiles to file, only single reference is allowed")
+	diffMaxDistance = diffCmd.Flags.Int("max-distance", align.DefaultOptions.MaxDistance,
+		"maximum edit distance of aligned blocks that are not complex")
+	diffMaxEdits = diffCmd.Flags.Int("max-edits", align.DefaultOptions.MaxEdits,
+		"maximum number of edits of aligned blocks that are not complex")
+	diffMinDepth          = diffCmd.Flags.Float64("min-depth", 0, "minimum read depth of blocks that are not unknown, 0 disables the check")
+	diffMinQual           = diffCmd.Flags.Int("min-qual", 0, "minimum quality of blocks that are not unknown, 0 disables the check")
+	diffMaxNoCalls        = diffCmd.Flags.Int("max-nocalls", 0, "maximum number of masked bases of blocks that are not unknown, 0 disables the check")
+	diffMaxNoCallFraction = diffCmd.Flags.Float64("max-nocall-fraction", 0,
+		"maximum fraction of masked bases of blocks that are not unknown, 0 disables the check")
+)
+
+func init() {
+	diffCmd.Run = runDiff
+	register(diffCmd)
+}
+
+// readDiffGenome reads tiled genome of sample and makes blocks that do not
+// meet thresholds of quality invalid, references are read by readGenome.
+func readDiffGenome(name string) (*genome.Sequence, error) {
+	s, err := readGenome(name)
+	if err != nil {
+		return nil, err
+	}
+	return diffThresholds().Filter(s), nil
+}
+
+func diffThresholds() genome.Thresholds {
+	return genome.Thresholds{
+		MinDepth:          *diffMinDepth,
+		MinQuality:        *diffMinQual,
+		MaxNoCalls:        *diffMaxNoCalls,
+		MaxNoCallFraction: *diffMaxNoCallFraction,
+	}
+}
+
+func runDiff(cmd *Command, args []string) error {
+	if len(args) < 2 {
+		return usageError("need a sample and at least one reference tiled genome files")
+	}
+	outputs := splitList(*diffOutput)
+	switch {
+	case len(args) == 2 && len(outputs) == 0:
+		outputs = []string{""}
+	case len(outputs) != len(args)-1:
+		return usageError("need one output file for every reference")
+	}
+
+	if *diffDiploid {
+		if len(args) != 2 {
+			return usageError("need exactly one reference for diploid sample")
+		}
+		return diffDiploidGenome(args[0], args[1], outputs[0])
+	}
+	if len(*diffEdits) > 0 {
+		if len(args) != 2 {
+			return usageError("need exactly one reference for edits")
+		}
+		return diffAligned(args[0], args[1], outputs[0], *diffEdits)
+	}
+
+	gs, err := readDiffGenome(args[0])
+	if err != nil {
+		return err
+	}
+	refs := make([]*genome.Sequence, len(args)-1)
+	for i, name := range args[1:] {
+		if refs[i], err = readGenome(name); err != nil {
+			return err
+		}
+	}
+	bss, err := lightning.ComputeDiffSeqs(gs, refs...)
+	if err != nil {
+		return err
+	}
+
+	for i, bs := range bss {
+		w, err := create(outputs[i])
+		if err != nil {
+			return err
+		}
+		if _, err = bs.WriteTo(w); err != nil {
+			w.Close()
+			return err
+		}
+		if err = w.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffDiploidGenome(sample, reference, output string) error {
+	f, err := os.Open(sample)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d, err := genome.ReadDiploid(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", sample, err)
+	}
+	th := diffThresholds()
+	d.Haplotypes[0], d.Haplotypes[1] = th.Filter(d.Haplotypes[0]), th.Filter(d.Haplotypes[1])
+	ref, err := readGenome(reference)
+	if err != nil {
+		return err
+	}
+	bs, err := lightning.ComputeDiffDiploid(d, ref, nil)
+	if err != nil {
+		return err
+	}
+
+	w, err := create(output)
+	if err != nil {
+		return err
+	}
+	if _, err = bs.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func diffAligned(sample, reference, output, edits string) error {
+	gs1, err := readDiffGenome(sample)
+	if err != nil {
+		return err
+	}
+	gs2, err := readGenome(reference)
+	if err != nil {
+		return err
+	}
+	opt := align.Options{MaxDistance: *diffMaxDistance, MaxEdits: *diffMaxEdits}
+	bs, tes, err := lightning.ComputeAlignedDiffSeq(gs1, gs2, opt)
+	if err != nil {
+		return err
+	}
+
+	w, err := create(output)
+	if err != nil {
+		return err
+	}
+	if _, err = bs.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	f, err := os.Create(edits)
+	if err != nil {
+		return err
+	}
+	if err = align.WriteEdits(f, tes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+
+	"github.com/genomelightning/lightning/bits"
+)
+
+var dumpCmd = &Command{
+	Name:  "dump",
+	Usage: "<diff>",
+	Short: "Dump bit sequence in text format",
+	Flags: flag.NewFlagSet("dump", flag.ContinueOnError),
+}
+
+var (
+	dumpFormat = dumpCmd.Flags.String("format", "tiles",
+		"output format: tiles, types, combines, words-bits or combines-bits")
+	dumpWidth   = dumpCmd.Flags.Int("width", 32, "number of tiles per row")
+	dumpCombine = dumpCmd.Flags.String("combine", "hex", "format of combine indexes: hex or pair")
+)
+
+func init() {
+	dumpCmd.Run = runDump
+	register(dumpCmd)
+}
+
+func runDump(cmd *Command, args []string) error {
+	if err := checkFormat(*dumpFormat, "tiles", "types", "combines",
+		"words-bits", "combines-bits"); err != nil {
+		return err
+	}
+	opt := bits.DumpOptions{Width: *dumpWidth}
+	switch *dumpCombine {
+	case "hex":
+	case "pair":
+		opt.Combine = bits.CF_PAIR
+	default:
+		return usageError("unknown combine format: " + *dumpCombine)
+	}
+	if len(args) != 1 {
+		return usageError("need exactly one bit sequence file")
+	}
+	bs, err := readDiff(args[0])
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	switch *dumpFormat {
+	case "tiles":
+		err = bits.Dump(w, bs, opt)
+	case "types":
+		err = bits.WriteTypes(w, bs, opt.Width)
+	case "combines":
+		err = bits.WriteCombines(w, bs, opt.Width, opt.Combine)
+	case "words-bits":
+		_, err = w.WriteString(bits.DumpWordsAsBits(bs))
+	case "combines-bits":
+		_, err = w.WriteString(bits.DumpCombinesAsBits(bs))
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
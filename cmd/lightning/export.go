@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/genomelightning/lightning/annot"
+	"github.com/genomelightning/lightning/bits"
+)
+
+var exportCmd = &Command{
+	Name:  "export",
+	Usage: "<diff>",
+	Short: "Export non-default tiles of bit sequence",
+	Flags: flag.NewFlagSet("export", flag.ContinueOnError),
+}
+
+var (
+	exportTiles  = exportCmd.Flags.String("tiles", "", "comma-separated list of tileset files for coordinates")
+	exportFormat = exportCmd.Flags.String("format", "tsv", "output format: json or tsv")
+	exportGenes  = exportCmd.Flags.String("genes", "", "GTF, GFF3 or BED file for gene annotation, needs tiles")
+)
+
+func init() {
+	exportCmd.Run = runExport
+	register(exportCmd)
+}
+
+// Record represents a non-default tile.
+type Record struct {
+	Tile    uint64   `json:"tile"`
+	Chr     string   `json:"chr,omitempty"`
+	Start   int64    `json:"start"`
+	End     int64    `json:"end"`
+	Type    string   `json:"type"`
+	Combine int      `json:"combine"`
+	Nums    [2]int   `json:"nums"`
+	Genes   []string `json:"genes,omitempty"`
+}
+
+func runExport(cmd *Command, args []string) error {
+	if err := checkFormat(*exportFormat, "json", "tsv"); err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("need exactly one bit sequence file")
+	}
+	bs, err := readDiff(args[0])
+	if err != nil {
+		return err
+	}
+	tiles, err := readTiles(*exportTiles)
+	if err != nil {
+		return err
+	}
+	if tiles != nil && len(tiles) != int(bs.Len()) {
+		return fmt.Errorf("%d tiles for bit sequence of length %d", len(tiles), bs.Len())
+	}
+
+	var a *annot.Annotation
+	if len(*exportGenes) > 0 {
+		if tiles == nil {
+			return usageError("need tiles for gene annotation")
+		}
+		if a, err = annot.Load(*exportGenes); err != nil {
+			return err
+		}
+	}
+
+	records := make([]*Record, 0, 1024)
+	bs.ForEachNonDefault(func(i uint64, dt bits.DiffType, combine int) {
+		r := &Record{Tile: i, Type: dt.String(), Combine: combine,
+			Nums: bits.CombinationTable[combine].Nums}
+		if tiles != nil {
+			r.Chr, r.Start, r.End = tiles[i].Chr, tiles[i].Start, tiles[i].End
+		}
+		if a != nil {
+			r.Genes = a.Genes(r.Chr, r.Start, r.End)
+		}
+		records = append(records, r)
+	})
+
+	if *exportFormat == "json" {
+		return writeJSON(os.Stdout, records)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "tile\tchr\tstart\tend\ttype\tcombine\tnum1\tnum2\tgenes")
+	for _, r := range records {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%d\t%d\t%d\t%s\n", r.Tile, r.Chr, r.Start, r.End,
+			r.Type, r.Combine, r.Nums[0], r.Nums[1], strings.Join(r.Genes, ","))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/genomelightning/lightning/annot"
+)
+
+var genesCmd = &Command{
+	Name:  "genes",
+	Usage: "<gene>...",
+	Short: "List tiles that overlap features of genes",
+	Flags: flag.NewFlagSet("genes", flag.ContinueOnError),
+}
+
+var (
+	genesAnnot  = genesCmd.Flags.String("annot", "", "GTF, GFF3 or BED annotation file")
+	genesTiles  = genesCmd.Flags.String("tiles", "", "comma-separated list of tileset files")
+	genesType   = genesCmd.Flags.String("type", "", "feature type, e.g. gene or exon, default is any type")
+	genesFormat = genesCmd.Flags.String("format", "tsv", "output format: json or tsv")
+)
+
+func init() {
+	genesCmd.Run = runGenes
+	register(genesCmd)
+}
+
+// GeneTile represents a tile that overlaps features of a gene.
+type GeneTile struct {
+	Gene  string `json:"gene"`
+	Tile  int    `json:"tile"`
+	Chr   string `json:"chr"`
+	Start int64  `json:"start"`
+	End   int64  `json:"end"`
+}
+
+func runGenes(cmd *Command, args []string) error {
+	if err := checkFormat(*genesFormat, "json", "tsv"); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageError("need gene names or IDs")
+	}
+	if len(*genesAnnot) == 0 {
+		return usageError("need annotation file")
+	}
+	tiles, err := readTiles(*genesTiles)
+	if err != nil {
+		return err
+	}
+	if len(tiles) == 0 {
+		return usageError("need tileset files")
+	}
+	a, err := annot.Load(*genesAnnot)
+	if err != nil {
+		return err
+	}
+
+	ti := annot.NewTileIndex(tiles)
+	records := make([]*GeneTile, 0, 64)
+	for _, name := range args {
+		fs := a.Find(name, *genesType)
+		if len(fs) == 0 {
+			return fmt.Errorf("no feature found for %q", name)
+		}
+		for _, i := range ti.Features(fs) {
+			t := tiles[i]
+			records = append(records, &GeneTile{name, i, t.Chr, t.Start, t.End})
+		}
+	}
+
+	if *genesFormat == "json" {
+		return writeJSON(os.Stdout, records)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "gene\ttile\tchr\tstart\tend")
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\n", r.Gene, r.Tile, r.Chr, r.Start, r.End)
+	}
+	return w.Flush()
+}
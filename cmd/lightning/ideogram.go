@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/assoc"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/ideogram"
+)
+
+var ideogramCmd = &Command{
+	Name:  "ideogram",
+	Usage: "",
+	Short: "Render chromosome ideograms with heat tracks in SVG format",
+	Flags: flag.NewFlagSet("ideogram", flag.ContinueOnError),
+}
+
+var (
+	ideogramMap     = ideogramCmd.Flags.String("map", "", "UCSC cytoband file")
+	ideogramHg      = ideogramCmd.Flags.Int("hg", 19, "version of human genome assembly")
+	ideogramTiles   = ideogramCmd.Flags.String("tiles", "", "comma-separated list of tileset files, needed by -diff and -density")
+	ideogramDiff    = ideogramCmd.Flags.String("diff", "", "bit sequence file for tracks of DiffType counts")
+	ideogramTypes   = ideogramCmd.Flags.String("types", "simple,complex", "comma-separated list of DiffTypes to count of -diff")
+	ideogramDensity = ideogramCmd.Flags.Bool("density", false, "add track of tile density")
+	ideogramPValues = ideogramCmd.Flags.String("pvalues", "", "results of assoc in TSV format for track of p-values")
+	ideogramChrs    = ideogramCmd.Flags.String("chrs", "", "comma-separated list of chromosomes, default is all")
+	ideogramTitle   = ideogramCmd.Flags.String("title", "", "title of figure")
+	ideogramWidth   = ideogramCmd.Flags.Int("width", ideogram.DefaultOptions.Width, "width of figure in pixels")
+	ideogramOutput  = ideogramCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	ideogramCmd.Run = runIdeogram
+	register(ideogramCmd)
+}
+
+// parseDiffType returns DiffType by name.
+func parseDiffType(name string) (bits.DiffType, error) {
+	for dt := bits.DT_DEFAULT; dt <= bits.DT_UNKNOWN; dt++ {
+		if dt.String() == name {
+			return dt, nil
+		}
+	}
+	return 0, usageError(fmt.Sprintf("unknown DiffType %q", name))
+}
+
+// readResults reads results with coordinates of assoc in TSV format.
+func readResults(name string) ([]*assoc.Result, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		results []*assoc.Result
+		cols    = map[string]int{}
+	)
+	snr := bufio.NewScanner(f)
+	for line := 1; snr.Scan(); line++ {
+		infos := strings.Split(snr.Text(), "\t")
+		if line == 1 {
+			for i, col := range infos {
+				cols[col] = i
+			}
+			for _, col := range []string{"chr", "start", "end", "pvalue"} {
+				if _, ok := cols[col]; !ok {
+					return nil, fmt.Errorf("%s: missing column %q", name, col)
+				}
+			}
+			continue
+		}
+		if len(infos) != len(cols) {
+			return nil, fmt.Errorf("%s:%d: expect %d fields but got %d", name, line, len(cols), len(infos))
+		}
+
+		res := &assoc.Result{Chr: infos[cols["chr"]]}
+		if infos[cols["pvalue"]] == "NA" {
+			res.Skipped = true
+		} else if res.PValue, err = strconv.ParseFloat(infos[cols["pvalue"]], 64); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+		if res.Start, err = strconv.ParseInt(infos[cols["start"]], 10, 64); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+		if res.End, err = strconv.ParseInt(infos[cols["end"]], 10, 64); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+		results = append(results, res)
+	}
+	return results, snr.Err()
+}
+
+func runIdeogram(cmd *Command, args []string) error {
+	if len(args) != 0 {
+		return usageError("no argument is needed")
+	}
+	if len(*ideogramMap) == 0 {
+		return usageError("need cytoband file")
+	}
+	cm, err := cytomap.ParseCytoMap(*ideogramHg, *ideogramMap)
+	if err != nil {
+		return err
+	}
+	tiles, err := readTiles(*ideogramTiles)
+	if err != nil {
+		return err
+	}
+	if tiles == nil && (len(*ideogramDiff) > 0 || *ideogramDensity) {
+		return usageError("need tileset files for tracks of tiles")
+	}
+
+	var tracks []*ideogram.Track
+	if len(*ideogramDiff) > 0 {
+		b, err := readDiff(*ideogramDiff)
+		if err != nil {
+			return err
+		}
+		for _, name := range splitList(*ideogramTypes) {
+			dt, err := parseDiffType(name)
+			if err != nil {
+				return err
+			}
+			t, err := ideogram.DiffCounts(cm, b, tiles, dt)
+			if err != nil {
+				return err
+			}
+			tracks = append(tracks, t)
+		}
+	}
+	if *ideogramDensity {
+		cm.AddTiles(tiles)
+		tracks = append(tracks, ideogram.TileDensity(cm))
+	}
+	if len(*ideogramPValues) > 0 {
+		results, err := readResults(*ideogramPValues)
+		if err != nil {
+			return err
+		}
+		tracks = append(tracks, ideogram.PValues(cm, results))
+	}
+
+	w, err := create(*ideogramOutput)
+	if err != nil {
+		return err
+	}
+	opt := ideogram.Options{Width: *ideogramWidth, Chrs: splitList(*ideogramChrs), Title: *ideogramTitle}
+	if err = ideogram.Render(w, cm, tracks, opt); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
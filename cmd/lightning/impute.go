@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/impute"
+)
+
+var imputeCmd = &Command{
+	Name:  "impute",
+	Usage: "<sample.genome>",
+	Short: "Impute unknown tiles of tiled genome from reference panel of haplotypes",
+	Flags: flag.NewFlagSet("impute", flag.ContinueOnError),
+}
+
+var (
+	imputeRef               = imputeCmd.Flags.String("ref", "", "reference tiled genome file for numbering variants")
+	imputePanel             = imputeCmd.Flags.String("panel", "", "comma-separated list of tiled genome files of reference panel")
+	imputeDiploid           = imputeCmd.Flags.Bool("diploid", false, "files of reference panel are diploid, both haplotypes are used")
+	imputeSwitch            = imputeCmd.Flags.Float64("switch", impute.DefaultOptions.Switch, "probability of switching haplotype between neighboring tiles")
+	imputeError             = imputeCmd.Flags.Float64("error", impute.DefaultOptions.Error, "probability of variant that differs from copied haplotype")
+	imputeFlank             = imputeCmd.Flags.Int("flank", impute.DefaultOptions.Flank, "number of tiles on each side of unknown tiles that are used")
+	imputeMinConf           = imputeCmd.Flags.Float64("min-conf", impute.DefaultOptions.MinConfidence, "minimum confidence of tiles that are imputed")
+	imputeMinDepth          = imputeCmd.Flags.Float64("min-depth", 0, "minimum read depth of blocks that are not imputed, 0 disables the check")
+	imputeMinQual           = imputeCmd.Flags.Int("min-qual", 0, "minimum quality of blocks that are not imputed, 0 disables the check")
+	imputeMaxNoCalls        = imputeCmd.Flags.Int("max-nocalls", 0, "maximum number of masked bases of blocks that are not imputed, 0 disables the check")
+	imputeMaxNoCallFraction = imputeCmd.Flags.Float64("max-nocall-fraction", 0,
+		"maximum fraction of masked bases of blocks that are not imputed, 0 disables the check")
+	imputeConfidence = imputeCmd.Flags.String("confidence", "", "output file of confidence of tiles in tab-separated format")
+	imputeOutput     = imputeCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	imputeCmd.Run = runImpute
+	register(imputeCmd)
+}
+
+func readPanel(names []string, diploid bool) ([]*genome.Sequence, error) {
+	var haps []*genome.Sequence
+	for _, name := range names {
+		if !diploid {
+			s, err := readGenome(name)
+			if err != nil {
+				return nil, err
+			}
+			haps = append(haps, s)
+			continue
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		d, err := genome.ReadDiploid(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		haps = append(haps, d.Haplotypes[0], d.Haplotypes[1])
+	}
+	return haps, nil
+}
+
+func runImpute(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("need exactly one tiled genome file")
+	}
+	if len(*imputeRef) == 0 {
+		return usageError("need reference tiled genome")
+	}
+	names := splitList(*imputePanel)
+	if len(names) == 0 {
+		return usageError("need tiled genome files of reference panel")
+	}
+	if *imputeSwitch < 0 || *imputeSwitch > 1 || *imputeError < 0 || *imputeError > 1 {
+		return usageError("probabilities must be in [0, 1]")
+	}
+	if *imputeFlank < 0 {
+		return usageError("flank cannot be negative")
+	}
+
+	ref, err := readGenome(*imputeRef)
+	if err != nil {
+		return err
+	}
+	haps, err := readPanel(names, *imputeDiploid)
+	if err != nil {
+		return err
+	}
+	p, err := impute.NewPanel(ref, haps)
+	if err != nil {
+		return err
+	}
+	s, err := readGenome(args[0])
+	if err != nil {
+		return err
+	}
+	th := genome.Thresholds{
+		MinDepth:          *imputeMinDepth,
+		MinQuality:        *imputeMinQual,
+		MaxNoCalls:        *imputeMaxNoCalls,
+		MaxNoCallFraction: *imputeMaxNoCallFraction,
+	}
+	opt := impute.Options{Switch: *imputeSwitch, Error: *imputeError, Flank: *imputeFlank, MinConfidence: *imputeMinConf}
+	fs, confs, err := p.Impute(s, th, opt)
+	if err != nil {
+		return err
+	}
+
+	w, err := create(*imputeOutput)
+	if err != nil {
+		return err
+	}
+	if err = fs.Write(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	if len(*imputeConfidence) > 0 {
+		f, err := os.Create(*imputeConfidence)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(f)
+		fmt.Fprintln(bw, "tile\timputed\tconfidence")
+		for i, conf := range confs {
+			// Blocks that are not imputed are shared or filtered, i.e. invalid.
+			fmt.Fprintf(bw, "%d\t%t\t%.4f\n", i, fs.Blocks[i] != s.Blocks[i] && fs.Blocks[i].Valid, conf)
+		}
+		if err = bw.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return nil
+}
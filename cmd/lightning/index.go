@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+var indexCmd = &Command{
+	Name:  "index",
+	Usage: "<reference.fa>",
+	Short: "Build genome-wide k-mer index of reference FASTA",
+	Flags: flag.NewFlagSet("index", flag.ContinueOnError),
+}
+
+var (
+	indexK      = indexCmd.Flags.Int("k", tileset.DefaultTagLen, "length of k-mers, at most 32")
+	indexOutput = indexCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	indexCmd.Run = runIndex
+	register(indexCmd)
+}
+
+func runIndex(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("need exactly one FASTA file")
+	}
+	if *indexK < 1 || *indexK > kmer.MaxK {
+		return usageError(fmt.Sprintf("k-mer length must be in [1, %d]", kmer.MaxK))
+	}
+	seqs, err := readFASTA(args[0])
+	if err != nil {
+		return err
+	}
+	idx, err := kmer.Build(seqs, *indexK)
+	if err != nil {
+		return err
+	}
+
+	w, err := create(*indexOutput)
+	if err != nil {
+		return err
+	}
+	if _, err = idx.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d k-mers in %d contigs\n", idx.Len(), len(idx.Contigs))
+	return nil
+}
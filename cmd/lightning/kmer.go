@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+)
+
+var kmerCmd = &Command{
+	Name:  "kmer",
+	Usage: "<sequence>...",
+	Short: "Look up positions and occurrence counts of k-mers in k-mer index",
+	Flags: flag.NewFlagSet("kmer", flag.ContinueOnError),
+}
+
+var (
+	kmerIndex = kmerCmd.Flags.String("index", "", "k-mer index built by index command")
+	kmerCount = kmerCmd.Flags.Bool("count", false, "only print counts in both strands")
+)
+
+func init() {
+	kmerCmd.Run = runKmer
+	register(kmerCmd)
+}
+
+func runKmer(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return usageError("need k-mer sequences")
+	}
+	if len(*kmerIndex) == 0 {
+		return usageError("need k-mer index")
+	}
+	idx, err := readIndex(*kmerIndex)
+	if err != nil {
+		return err
+	}
+	for _, seq := range args {
+		if len(seq) != idx.K {
+			return usageError(fmt.Sprintf("length of %s is not %d", seq, idx.K))
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	for _, seq := range args {
+		if *kmerCount {
+			fmt.Fprintf(w, "%s\t%d\n", seq, idx.CountCanonical([]byte(seq)))
+			continue
+		}
+		for _, p := range idx.Lookup([]byte(seq)) {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", seq, p.Chr, p.Pos)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/liftover"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+var liftoverCmd = &Command{
+	Name:  "liftover",
+	Usage: "<file.chain>",
+	Short: "Convert coordinates of tiles or bands between assemblies by chain file",
+	Flags: flag.NewFlagSet("liftover", flag.ContinueOnError),
+}
+
+var (
+	liftoverTiles    = liftoverCmd.Flags.String("tiles", "", "comma-separated list of tileset files to convert")
+	liftoverMap      = liftoverCmd.Flags.String("map", "", "UCSC cytoband file to convert")
+	liftoverFrom     = liftoverCmd.Flags.String("from", "hg19", "source assembly of cytoband file, e.g. hg19 or GRCh37")
+	liftoverTo       = liftoverCmd.Flags.String("to", "hg38", "destination assembly")
+	liftoverMinMatch = liftoverCmd.Flags.Float64("min-match", liftover.DefaultMinMatch, "minimum fraction of bases that must be lifted")
+	liftoverOutput   = liftoverCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	liftoverCmd.Run = runLiftover
+	register(liftoverCmd)
+}
+
+func runLiftover(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("need exactly one chain file")
+	}
+	if (len(*liftoverTiles) == 0) == (len(*liftoverMap) == 0) {
+		return usageError("need either tileset files or cytoband file")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	c, err := liftover.ReadChain(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", args[0], err)
+	}
+	c.MinMatch = *liftoverMinMatch
+
+	w, err := create(*liftoverOutput)
+	if err != nil {
+		return err
+	}
+	if len(*liftoverTiles) > 0 {
+		err = liftTiles(w, c)
+	} else {
+		err = liftMap(w, c)
+	}
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// liftTiles writes lifted tiles, tiles that cannot be lifted are reported
+// to standard error and skipped.
+func liftTiles(w io.Writer, c *liftover.Chains) error {
+	tiles, err := readTiles(*liftoverTiles)
+	if err != nil {
+		return err
+	}
+	lifted, unmapped := c.LiftTiles(tiles)
+	for _, i := range unmapped {
+		fmt.Fprintf(os.Stderr, "unmapped tile %d: %s:%d-%d\n", i, tiles[i].Chr, tiles[i].Start, tiles[i].End)
+	}
+	mapped := make([]*tileset.Tile, 0, len(lifted))
+	for _, t := range lifted {
+		if t != nil {
+			mapped = append(mapped, t)
+		}
+	}
+	return tileset.Write(w, mapped)
+}
+
+// liftMap writes lifted bands in UCSC cytoband format, bands that cannot be
+// lifted are reported to standard error and skipped.
+func liftMap(w io.Writer, c *liftover.Chains) error {
+	cm, err := cytomap.Load(cytomap.LookupAssembly(*liftoverFrom), *liftoverMap)
+	if err != nil {
+		return err
+	}
+	lcm, unmapped := c.LiftMap(cm, cytomap.LookupAssembly(*liftoverTo))
+	for _, rule := range unmapped {
+		fmt.Fprintf(os.Stderr, "unmapped band %s:%s\n", rule.Chr, rule.Section)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#chrom\tchromStart\tchromEnd\tname\tgieStain")
+	for _, rule := range lcm.Rules {
+		fmt.Fprintf(bw, "%s\t%d\t%d\t%s\t%s\n", rule.Chr, rule.Start, rule.End, rule.Section, rule.Color)
+	}
+	return bw.Flush()
+}
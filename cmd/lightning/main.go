@@ -0,0 +1,200 @@
+// Lightning is a command-line tool for processing tiled genome data.
+//
+// Usage:
+//
+//	lightning <command> [flags] [arguments]
+//
+// Exit code is 0 on success, 1 on runtime error and 2 on usage error.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Command represents a subcommand.
+type Command struct {
+	Name  string
+	Usage string // Arguments after flags.
+	Short string
+	Flags *flag.FlagSet
+	Run   func(cmd *Command, args []string) error
+}
+
+var commands = map[string]*Command{}
+
+func register(cmd *Command) {
+	cmd.Flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: lightning %s [flags] %s\n\n%s.\n\n", cmd.Name, cmd.Usage, cmd.Short)
+		cmd.Flags.PrintDefaults()
+	}
+	commands[cmd.Name] = cmd
+}
+
+// usageError is returned when command is called with invalid arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: lightning <command> [flags] [arguments]\n\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].Short)
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "lightning: unknown command %q\n", args[0])
+		usage()
+		return 2
+	}
+
+	if err := cmd.Flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if err := cmd.Run(cmd, cmd.Flags.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "lightning %s: %v\n", cmd.Name, err)
+		var ue usageError
+		if errors.As(err, &ue) {
+			cmd.Flags.Usage()
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// checkFormat returns usage error when format is not one of given formats.
+func checkFormat(format string, formats ...string) error {
+	for _, f := range formats {
+		if format == f {
+			return nil
+		}
+	}
+	return usageError(fmt.Sprintf("unknown format %q, must be one of %s",
+		format, strings.Join(formats, ", ")))
+}
+
+// create creates output file, it uses standard output when name is empty or "-".
+func create(name string) (io.WriteCloser, error) {
+	if len(name) == 0 || name == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(name)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// splitList splits comma-separated list, empty items are ignored.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// readDiff reads bit sequence in either dense or compressed form.
+func readDiff(name string) (bits.Bitmap, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := bits.ReadBitmap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return s, nil
+}
+
+func readGenome(name string) (*genome.Sequence, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := genome.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return s, nil
+}
+
+func readFASTA(name string) (map[string][]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	seqs, err := genome.ReadFASTA(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return seqs, nil
+}
+
+func readIndex(name string) (*kmer.Index, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	idx, err := kmer.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return idx, nil
+}
+
+// readTiles reads tiles from comma-separated list of tileset files,
+// it returns nil when list is empty.
+func readTiles(list string) ([]*tileset.Tile, error) {
+	names := splitList(list)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return tileset.ReadFiles(names...)
+}
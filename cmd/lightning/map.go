@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/genomelightning/lightning/align"
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/readmap"
+)
+
+var mapCmd = &Command{
+	Name:  "map",
+	Usage: "<reads.fq>...",
+	Short: "Assign FASTQ reads to tiles by tags and build tiled genome of consensus",
+	Flags: flag.NewFlagSet("map", flag.ContinueOnError),
+}
+
+var (
+	mapTiles       = mapCmd.Flags.String("tiles", "", "comma-separated list of tileset files")
+	mapK           = mapCmd.Flags.Int("tag", readmap.DefaultOptions.K, "length of tags that neighboring tiles share")
+	mapMinDepth    = mapCmd.Flags.Int("min-depth", readmap.DefaultOptions.MinDepth, "minimum depth of every base of valid tiles")
+	mapMinQual     = mapCmd.Flags.Int("min-qual", readmap.DefaultOptions.MinQual, "minimum Phred quality of bases that are counted")
+	mapMaxIndel    = mapCmd.Flags.Int("max-indel", readmap.DefaultOptions.MaxIndel, "maximum length difference of reads and reference between tags")
+	mapMaxDistance = mapCmd.Flags.Int("max-distance", readmap.DefaultOptions.Align.MaxDistance,
+		"maximum edit distance of parts of reads that are piled up")
+	mapMaxEdits = mapCmd.Flags.Int("max-edits", readmap.DefaultOptions.Align.MaxEdits,
+		"maximum number of edits of parts of reads that are piled up")
+	mapIndex  = mapCmd.Flags.String("index", "", "k-mer index of reference built by index command, built from tiles by default")
+	mapDepth  = mapCmd.Flags.String("depth", "", "output file of mean depth and quality of tiles in tab-separated format")
+	mapOutput = mapCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	mapCmd.Run = runMap
+	register(mapCmd)
+}
+
+// readFASTQ reads FASTQ file, file with ".gz" extension is gzip compressed.
+func readFASTQ(name string, fn func(*readmap.Record) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(name), ".gz") {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+	if err = readmap.ReadFASTQ(r, fn); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+func runMap(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return usageError("need FASTQ files")
+	}
+	tiles, err := readTiles(*mapTiles)
+	if err != nil {
+		return err
+	}
+	if len(tiles) == 0 {
+		return usageError("need tileset files")
+	}
+	if *mapMaxIndel < 0 {
+		return usageError("maximum length of indels cannot be negative")
+	}
+	var idx *kmer.Index
+	if len(*mapIndex) > 0 {
+		if idx, err = readIndex(*mapIndex); err != nil {
+			return err
+		}
+	}
+	m, err := readmap.New(tiles, idx, readmap.Options{
+		K:        *mapK,
+		MinDepth: *mapMinDepth,
+		MinQual:  *mapMinQual,
+		MaxIndel: *mapMaxIndel,
+		Align:    align.Options{MaxDistance: *mapMaxDistance, MaxEdits: *mapMaxEdits},
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err = readFASTQ(name, func(rec *readmap.Record) error {
+			m.Add(rec)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	s := m.Sequence()
+
+	w, err := create(*mapOutput)
+	if err != nil {
+		return err
+	}
+	if err = s.Write(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	if len(*mapDepth) > 0 {
+		f, err := os.Create(*mapDepth)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(f)
+		fmt.Fprintln(bw, "tile\tchr\tstart\tend\tdepth\tqual\tvalid")
+		for i, t := range tiles {
+			b := s.Blocks[i]
+			fmt.Fprintf(bw, "%d\t%s\t%d\t%d\t%.2f\t%d\t%t\n", i, t.Chr, t.Start, t.End, b.Depth, b.Quality, b.Valid)
+		}
+		if err = bw.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d reads mapped, %d unmapped, %d conflicting, %d unaligned\n",
+		m.Mapped, m.Unmapped, m.Conflict, m.Unaligned)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/server"
+)
+
+var serveCmd = &Command{
+	Name:  "serve",
+	Usage: "",
+	Short: "Serve HTTP queries over tiled genome store",
+	Flags: flag.NewFlagSet("serve", flag.ContinueOnError),
+}
+
+var (
+	serveAddr  = serveCmd.Flags.String("addr", "localhost:8080", "address to listen on")
+	serveMap   = serveCmd.Flags.String("map", "", "UCSC cytoband file")
+	serveHg    = serveCmd.Flags.Int("hg", 19, "version of human genome assembly")
+	serveTiles = serveCmd.Flags.String("tiles", "", "comma-separated list of tileset files")
+	serveDiffs = serveCmd.Flags.String("diffs", "", "directory of bit sequence files(*"+server.DiffExt+")")
+)
+
+func init() {
+	serveCmd.Run = runServe
+	register(serveCmd)
+}
+
+func runServe(cmd *Command, args []string) error {
+	if len(args) != 0 {
+		return usageError("unexpected arguments")
+	}
+	tiles, err := readTiles(*serveTiles)
+	if err != nil {
+		return err
+	}
+	if len(tiles) == 0 {
+		return usageError("need tileset files")
+	}
+	var cm *cytomap.CytoMap
+	if len(*serveMap) > 0 {
+		if cm, err = cytomap.ParseCytoMap(*serveHg, *serveMap); err != nil {
+			return err
+		}
+	}
+	samples := make(map[string]bits.Bitmap)
+	if len(*serveDiffs) > 0 {
+		if samples, err = server.LoadSamples(*serveDiffs); err != nil {
+			return err
+		}
+	}
+
+	s, err := server.New(cm, tiles, samples)
+	if err != nil {
+		return err
+	}
+	log.Printf("Listening on %s with %d tiles and %d samples", *serveAddr, len(tiles), len(samples))
+	return http.ListenAndServe(*serveAddr, s)
+}
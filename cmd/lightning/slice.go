@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+)
+
+var sliceCmd = &Command{
+	Name:  "slice",
+	Usage: "<diff>",
+	Short: "Extract tiles of range or cytoband from bit sequence",
+	Flags: flag.NewFlagSet("slice", flag.ContinueOnError),
+}
+
+var (
+	sliceRange  = sliceCmd.Flags.String("range", "", "range of tile indexes in format start-end, end is exclusive")
+	sliceBand   = sliceCmd.Flags.String("band", "", "cytoband in format chr:name, needs -map and -tiles")
+	sliceMap    = sliceCmd.Flags.String("map", "", "UCSC cytoband file")
+	sliceHg     = sliceCmd.Flags.Int("hg", 19, "version of human genome assembly")
+	sliceTiles  = sliceCmd.Flags.String("tiles", "", "comma-separated list of tileset files")
+	sliceOutput = sliceCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	sliceCmd.Run = runSlice
+	register(sliceCmd)
+}
+
+// dense converts bitmap to dense sequence when needed.
+func dense(b bits.Bitmap) *bits.Sequence {
+	switch s := b.(type) {
+	case *bits.Sequence:
+		return s
+	case *bits.Compressed:
+		return s.Dense()
+	}
+	panic(fmt.Sprintf("unexpected bitmap type: %T", b))
+}
+
+// bandRange returns range of tile indexes of cytoband.
+func bandRange() (start, end uint64, err error) {
+	i := strings.LastIndex(*sliceBand, ":")
+	if i <= 0 || len(*sliceMap) == 0 || len(*sliceTiles) == 0 {
+		return 0, 0, usageError("need -band in format chr:name, -map and -tiles")
+	}
+	cm, err := cytomap.ParseCytoMap(*sliceHg, *sliceMap)
+	if err != nil {
+		return 0, 0, err
+	}
+	rule := cm.FindBand((*sliceBand)[:i], (*sliceBand)[i+1:])
+	if rule == nil {
+		return 0, 0, fmt.Errorf("band not found: %s", *sliceBand)
+	}
+	tiles, err := readTiles(*sliceTiles)
+	if err != nil {
+		return 0, 0, err
+	}
+	s, e := rule.TileRange(tiles)
+	return uint64(s), uint64(e), nil
+}
+
+func runSlice(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("need exactly one bit sequence file")
+	}
+
+	var start, end uint64
+	var err error
+	switch {
+	case len(*sliceRange) > 0 && len(*sliceBand) == 0:
+		idxes := strings.SplitN(*sliceRange, "-", 2)
+		if len(idxes) != 2 {
+			return usageError("invalid range: " + *sliceRange)
+		}
+		if start, err = strconv.ParseUint(idxes[0], 10, 64); err != nil {
+			return usageError("invalid range: " + *sliceRange)
+		}
+		if end, err = strconv.ParseUint(idxes[1], 10, 64); err != nil {
+			return usageError("invalid range: " + *sliceRange)
+		}
+	case len(*sliceBand) > 0 && len(*sliceRange) == 0:
+		if start, end, err = bandRange(); err != nil {
+			return err
+		}
+	default:
+		return usageError("need exactly one of -range and -band")
+	}
+
+	b, err := readDiff(args[0])
+	if err != nil {
+		return err
+	}
+	bs, err := dense(b).Slice(start, end)
+	if err != nil {
+		return err
+	}
+
+	w, err := create(*sliceOutput)
+	if err != nil {
+		return err
+	}
+	if _, err = bs.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
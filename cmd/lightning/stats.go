@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/genomelightning/lightning/bits"
+)
+
+var statsCmd = &Command{
+	Name:  "stats",
+	Usage: "<diff>...",
+	Short: "Count tiles of each DiffType and combination in bit sequences",
+	Flags: flag.NewFlagSet("stats", flag.ContinueOnError),
+}
+
+var statsFormat = statsCmd.Flags.String("format", "json", "output format: json or tsv")
+
+func init() {
+	statsCmd.Run = runStats
+	register(statsCmd)
+}
+
+// Stats represents counts of tiles of a bit sequence.
+type Stats struct {
+	File     string         `json:"file"`
+	Length   uint32         `json:"length"`
+	Types    map[string]int `json:"types"`
+	Combines map[int]int    `json:"combines"` // Combine indexes of non-default tiles.
+}
+
+func computeStats(name string, bs bits.Bitmap) *Stats {
+	st := &Stats{
+		File:     name,
+		Length:   bs.Len(),
+		Types:    make(map[string]int),
+		Combines: make(map[int]int),
+	}
+	nonDefault := 0
+	bs.ForEachNonDefault(func(i uint64, dt bits.DiffType, combine int) {
+		st.Types[dt.String()]++
+		if dt != bits.DT_UNKNOWN {
+			st.Combines[combine]++
+		}
+		nonDefault++
+	})
+	st.Types[bits.DT_DEFAULT.String()] = int(bs.Len()) - nonDefault
+	return st
+}
+
+func runStats(cmd *Command, args []string) error {
+	if err := checkFormat(*statsFormat, "json", "tsv"); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageError("need at least one bit sequence file")
+	}
+
+	stats := make([]*Stats, len(args))
+	for i, name := range args {
+		bs, err := readDiff(name)
+		if err != nil {
+			return err
+		}
+		stats[i] = computeStats(name, bs)
+	}
+
+	if *statsFormat == "json" {
+		return writeJSON(os.Stdout, stats)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "file\tlength\tdefault\tsimple\tcomplex\tunknown")
+	for _, st := range stats {
+		fmt.Fprintf(w, "%s\t%d", st.File, st.Length)
+		for dt := bits.DT_DEFAULT; dt <= bits.DT_UNKNOWN; dt++ {
+			fmt.Fprintf(w, "\t%d", st.Types[dt.String()])
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
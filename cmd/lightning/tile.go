@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/genomelightning/lightning/genome"
+)
+
+var tileCmd = &Command{
+	Name:  "tile",
+	Usage: "<sample.fa> [<haplotype2.fa>]",
+	Short: "Cut FASTA sequences into tiled genome by tileset, two files make a diploid genome",
+	Flags: flag.NewFlagSet("tile", flag.ContinueOnError),
+}
+
+var (
+	tileTiles    = tileCmd.Flags.String("tiles", "", "comma-separated list of tileset files")
+	tileOutput   = tileCmd.Flags.String("o", "", "output file, default is standard output")
+	tilePhased   = tileCmd.Flags.Bool("phased", true, "haplotypes of diploid genome are phased")
+	tileSoftMask = tileCmd.Flags.Bool("soft-mask", false, "mask soft-masked (lower case) bases as not called")
+)
+
+func init() {
+	tileCmd.Run = runTile
+	register(tileCmd)
+}
+
+func runTile(cmd *Command, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("need one FASTA file or two FASTA files of haplotypes")
+	}
+	tiles, err := readTiles(*tileTiles)
+	if err != nil {
+		return err
+	}
+	if len(tiles) == 0 {
+		return usageError("need tileset files")
+	}
+
+	haps := make([]*genome.Sequence, len(args))
+	for i, name := range args {
+		seqs, err := readFASTA(name)
+		if err != nil {
+			return err
+		}
+		haps[i] = genome.Tile(seqs, tiles, *tileSoftMask)
+	}
+
+	w, err := create(*tileOutput)
+	if err != nil {
+		return err
+	}
+	if len(haps) == 1 {
+		err = haps[0].Write(w)
+	} else {
+		var d *genome.Diploid
+		if d, err = genome.NewDiploid(haps[0], haps[1], *tilePhased); err == nil {
+			err = d.Write(w)
+		}
+	}
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tilegen"
+)
+
+var tilegenCmd = &Command{
+	Name:  "tilegen",
+	Usage: "<reference.fa>",
+	Short: "Generate reference tileset and tag library from reference FASTA",
+	Flags: flag.NewFlagSet("tilegen", flag.ContinueOnError),
+}
+
+var (
+	tilegenK       = tilegenCmd.Flags.Int("k", tilegen.DefaultOptions.K, "length of tags")
+	tilegenSpacing = tilegenCmd.Flags.Int("spacing", tilegen.DefaultOptions.Spacing, "target distance between starts of neighboring tags")
+	tilegenShard   = tilegenCmd.Flags.Int("shard", 10000, "maximum number of tiles per tileset file")
+	tilegenDir     = tilegenCmd.Flags.String("o", ".", "output directory of tileset files and tags.tsv")
+	tilegenIndex   = tilegenCmd.Flags.String("index", "", "k-mer index of reference built by index command, built in memory by default")
+)
+
+func init() {
+	tilegenCmd.Run = runTilegen
+	register(tilegenCmd)
+}
+
+func runTilegen(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("need exactly one FASTA file")
+	}
+	if *tilegenShard <= 0 {
+		return usageError("shard size must be positive")
+	}
+	seqs, err := readFASTA(args[0])
+	if err != nil {
+		return err
+	}
+	var idx *kmer.Index
+	if len(*tilegenIndex) > 0 {
+		if idx, err = readIndex(*tilegenIndex); err != nil {
+			return err
+		}
+	}
+	tiles, tags, err := tilegen.Generate(seqs, idx, tilegen.Options{K: *tilegenK, Spacing: *tilegenSpacing})
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(*tilegenDir, 0755); err != nil {
+		return err
+	}
+	names, err := tilegen.WriteShards(*tilegenDir, tiles, *tilegenShard)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(*tilegenDir, "tags.tsv"))
+	if err != nil {
+		return err
+	}
+	if err = tilegen.WriteTags(f, tags); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d tiles in %d files, %d tags\n", len(tiles), len(names), len(tags))
+	return nil
+}
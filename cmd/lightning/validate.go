@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+var validateCmd = &Command{
+	Name:  "validate",
+	Usage: "<tileset.fa>...",
+	Short: "Check integrity of tileset files and their assignment to cytobands",
+	Flags: flag.NewFlagSet("validate", flag.ContinueOnError),
+}
+
+var (
+	validateTag   = validateCmd.Flags.Int("tag", tileset.DefaultTagLen, "length of tags that neighboring tiles share")
+	validateMap   = validateCmd.Flags.String("map", "", "UCSC cytoband file to check that every tile is in a band")
+	validateHg    = validateCmd.Flags.Int("hg", 19, "version of human genome assembly")
+	validateIndex = validateCmd.Flags.String("index", "", "k-mer index of reference to check that tags are unique")
+)
+
+func init() {
+	validateCmd.Run = runValidate
+	register(validateCmd)
+}
+
+func runValidate(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return usageError("need tileset files")
+	}
+	if *validateTag < 0 {
+		return usageError("tag length cannot be negative")
+	}
+
+	v := tileset.NewValidator(*validateTag)
+	var checks []func(*tileset.Tile) string
+	if len(*validateMap) > 0 {
+		cm, err := cytomap.ParseCytoMap(*validateHg, *validateMap)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, cm.CheckTile)
+	}
+	if len(*validateIndex) > 0 {
+		idx, err := readIndex(*validateIndex)
+		if err != nil {
+			return err
+		}
+		if idx.K != *validateTag {
+			return usageError(fmt.Sprintf("k-mer length %d of index differs from tag length %d", idx.K, *validateTag))
+		}
+		checks = append(checks, func(t *tileset.Tile) string {
+			// Every tile except the first one of chromosome starts with a tag.
+			if t.Start == 0 || len(t.Data) < idx.K {
+				return ""
+			}
+			if n := idx.CountCanonical(t.Data[:idx.K]); n != 1 {
+				return fmt.Sprintf("tag %s appears %d times in genome", t.Data[:idx.K], n)
+			}
+			return ""
+		})
+	}
+	if len(checks) > 0 {
+		v.Check = func(t *tileset.Tile) string {
+			for _, check := range checks {
+				if msg := check(t); len(msg) > 0 {
+					return msg
+				}
+			}
+			return ""
+		}
+	}
+	if err := v.ValidateFiles(args...); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	for _, issue := range v.Issues {
+		fmt.Fprintln(w, issue)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(v.Issues) > 0 {
+		return fmt.Errorf("found %d issues in %d tiles", len(v.Issues), v.Tiles)
+	}
+	return nil
+}
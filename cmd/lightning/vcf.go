@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/genomelightning/lightning/align"
+)
+
+var vcfCmd = &Command{
+	Name:  "vcf",
+	Usage: "<edits>",
+	Short: "Export edits of aligned tiles in VCF format",
+	Flags: flag.NewFlagSet("vcf", flag.ContinueOnError),
+}
+
+var (
+	vcfTiles  = vcfCmd.Flags.String("tiles", "", "comma-separated list of tileset files of reference")
+	vcfRef    = vcfCmd.Flags.String("ref", "", "tiled genome of reference")
+	vcfSample = vcfCmd.Flags.String("sample", "", "sample name, default is base name of edits file")
+	vcfOutput = vcfCmd.Flags.String("o", "", "output file, default is standard output")
+)
+
+func init() {
+	vcfCmd.Run = runVCF
+	register(vcfCmd)
+}
+
+func runVCF(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return usageError("need exactly one edits file")
+	}
+	if len(*vcfRef) == 0 {
+		return usageError("need tiled genome of reference")
+	}
+	tiles, err := readTiles(*vcfTiles)
+	if err != nil {
+		return err
+	}
+	if len(tiles) == 0 {
+		return usageError("need tileset files")
+	}
+	ref, err := readGenome(*vcfRef)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tes, err := align.ReadEdits(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", args[0], err)
+	}
+
+	sample := *vcfSample
+	if len(sample) == 0 {
+		sample = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+	}
+	w, err := create(*vcfOutput)
+	if err != nil {
+		return err
+	}
+	if err = align.WriteVCF(w, sample, tes, ref, tiles); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
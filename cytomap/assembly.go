@@ -0,0 +1,112 @@
+package cytomap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Assembly represents a genome assembly.
+type Assembly struct {
+	Name  string // UCSC name, e.g. "hg19", or name of custom assembly.
+	Alias string // NCBI name, e.g. "GRCh37".
+	Hg    int    // Version of human genome, 0 for custom assembly.
+}
+
+// Known human genome assemblies.
+var (
+	HG18 = &Assembly{"hg18", "NCBI36", 18}
+	HG19 = &Assembly{"hg19", "GRCh37", 19}
+	HG38 = &Assembly{"hg38", "GRCh38", 38}
+)
+
+var assemblies = []*Assembly{HG18, HG19, HG38}
+
+// String returns name of assembly.
+func (asm *Assembly) String() string {
+	return asm.Name
+}
+
+// LookupAssembly returns known assembly by UCSC name, NCBI name or version,
+// e.g. "hg19", "GRCh37" or "19", names are case-insensitive.
+// It returns a custom assembly with given name when no one is known.
+func LookupAssembly(name string) *Assembly {
+	for _, asm := range assemblies {
+		if strings.EqualFold(name, asm.Name) || strings.EqualFold(name, asm.Alias) ||
+			name == fmt.Sprint(asm.Hg) {
+			return asm
+		}
+	}
+	return &Assembly{Name: name}
+}
+
+// AssemblyOf returns known assembly by version of human genome,
+// it returns a custom assembly named "hg<version>" when no one is known.
+func AssemblyOf(hg int) *Assembly {
+	for _, asm := range assemblies {
+		if asm.Hg == hg {
+			return asm
+		}
+	}
+	return &Assembly{Name: fmt.Sprintf("hg%d", hg), Hg: hg}
+}
+
+// NormalizeChr returns chromosome name in UCSC style, e.g. "1" and "chr1" are
+// "chr1", "MT" and "chrM" are "chrM". Names of other sequences are unchanged.
+func NormalizeChr(chr string) string {
+	name := chr
+	if len(name) > 3 && strings.EqualFold(name[:3], "chr") {
+		name = name[3:]
+	}
+	switch strings.ToUpper(name) {
+	case "M", "MT":
+		return "chrM"
+	case "X":
+		return "chrX"
+	case "Y":
+		return "chrY"
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < '0' || name[i] > '9' {
+			return chr
+		}
+	}
+	if len(name) == 0 || name[0] == '0' {
+		return chr
+	}
+	if len(chr) == len(name)+3 && chr[:3] == "chr" {
+		return chr
+	}
+	return "chr" + name
+}
+
+// chrKey returns sort key of chromosome, numbered ones are first and
+// then X, Y, M and others by name.
+func chrKey(chr string) (int, string) {
+	name := strings.TrimPrefix(NormalizeChr(chr), "chr")
+	if n, err := strconv.Atoi(name); err == nil {
+		return n, ""
+	}
+	switch name {
+	case "X":
+		return 1000, ""
+	case "Y":
+		return 1001, ""
+	case "M":
+		return 1002, ""
+	}
+	return 1003, name
+}
+
+// SortChrs sorts chromosomes in natural order, e.g. chr2 is before chr10.
+func SortChrs(chrs []string) {
+	sort.Slice(chrs, func(i, j int) bool {
+		ni, si := chrKey(chrs[i])
+		nj, sj := chrKey(chrs[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return si < sj
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/genomelightning/lightning/interval"
 	"github.com/genomelightning/lightning/tileset"
 )
 
@@ -22,14 +23,22 @@ type CytoRule struct {
 	Tiles      []*tileset.Tile
 }
 
-// CytoMap represents cytomap of human genome.
+// CytoMap represents cytomap of human genome,
+// chromosome names of rules are in UCSC style.
 type CytoMap struct {
-	Hg    int
-	Rules []*CytoRule
+	Hg       int
+	Assembly *Assembly
+	Rules    []*CytoRule
+	index    *interval.Index // Index of rules, nil or stale index is not used.
 }
 
-// ParseCytoMap parses UCSC cytomap file.
+// ParseCytoMap parses UCSC cytomap file of human genome by version.
 func ParseCytoMap(hgNum int, fileName string) (*CytoMap, error) {
+	return Load(AssemblyOf(hgNum), fileName)
+}
+
+// Load parses UCSC cytomap file of assembly.
+func Load(asm *Assembly, fileName string) (*CytoMap, error) {
 	f, err := os.Open(fileName)
 	if err != nil {
 		return nil, err
@@ -41,7 +50,7 @@ func ParseCytoMap(hgNum int, fileName string) (*CytoMap, error) {
 	// Jump over the first line.
 	snr.Scan()
 
-	cm := &CytoMap{Hg: hgNum}
+	cm := &CytoMap{Hg: asm.Hg, Assembly: asm}
 	cm.Rules = make([]*CytoRule, 0, 1000)
 
 	// Parsing lines.
@@ -52,7 +61,7 @@ func ParseCytoMap(hgNum int, fileName string) (*CytoMap, error) {
 		}
 
 		rule := &CytoRule{
-			Chr:     infos[0],
+			Chr:     NormalizeChr(infos[0]),
 			Section: infos[3],
 			Color:   infos[4],
 		}
@@ -68,23 +77,116 @@ func ParseCytoMap(hgNum int, fileName string) (*CytoMap, error) {
 		rule.End = endIdx
 		cm.Rules = append(cm.Rules, rule)
 	}
+	cm.BuildIndex()
 	return cm, nil
 }
 
-// getRule finds the rule and set data that fits the range of tile.
-// It returns false when no rule found.
-func (cm *CytoMap) checkRule(chr string, start, end int64, data []byte) bool {
+// BuildIndex builds interval index of rules for lookup, it has to be called
+// again after rules are changed, otherwise lookup falls back to linear scan.
+func (cm *CytoMap) BuildIndex() {
+	cm.index = interval.NewIndex()
+	for _, rule := range cm.Rules {
+		cm.index.Add(NormalizeChr(rule.Chr), rule.Start, rule.End)
+	}
+	cm.index.Build()
+}
+
+// Find returns the rule that contains given range,
+// it returns nil when no rule found.
+func (cm *CytoMap) Find(chr string, start, end int64) *CytoRule {
+	chr = NormalizeChr(chr)
+	if cm.index != nil && cm.index.Len() == len(cm.Rules) {
+		ids := cm.index.Contain(chr, start, end)
+		if len(ids) == 0 {
+			return nil
+		}
+		// Keep the first rule in order when rules overlap.
+		min := ids[0]
+		for _, id := range ids[1:] {
+			if id < min {
+				min = id
+			}
+		}
+		return cm.Rules[min]
+	}
+
 	for _, rule := range cm.Rules {
-		if rule.Chr != chr {
+		if NormalizeChr(rule.Chr) != chr {
 			continue
 		}
 
 		if start >= rule.Start && end <= rule.End {
-			rule.Tiles = append(rule.Tiles, &tileset.Tile{data})
-			return true
+			return rule
+		}
+	}
+	return nil
+}
+
+// TileRange returns range [start, end) of indexes of tiles that are in the rule,
+// tiles in the rule are assumed to be continuous. It returns 0, 0 when no tile found.
+func (rule *CytoRule) TileRange(tiles []*tileset.Tile) (start, end int) {
+	start = -1
+	for i, t := range tiles {
+		if NormalizeChr(t.Chr) == NormalizeChr(rule.Chr) && t.Start >= rule.Start && t.End <= rule.End {
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+		} else if start >= 0 {
+			break
+		}
+	}
+	if start < 0 {
+		return 0, 0
+	}
+	return start, end
+}
+
+// FindBand returns the rule by chromosome and band name, it returns nil when no rule found.
+func (cm *CytoMap) FindBand(chr, name string) *CytoRule {
+	chr = NormalizeChr(chr)
+	for _, rule := range cm.Rules {
+		if NormalizeChr(rule.Chr) == chr && rule.Section == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// checkRule finds the rule and set data that fits the range of tile.
+// It returns false when no rule found.
+func (cm *CytoMap) checkRule(chr string, start, end int64, data []byte) bool {
+	rule := cm.Find(chr, start, end)
+	if rule == nil {
+		return false
+	}
+	rule.Tiles = append(rule.Tiles, &tileset.Tile{
+		Chr:   chr,
+		Start: start,
+		End:   end,
+		Data:  append([]byte(nil), data...),
+	})
+	return true
+}
+
+// CheckTile returns message of inconsistency when tile is not contained by any
+// rule, or empty string for none. It is used as check of tileset.Validator.
+func (cm *CytoMap) CheckTile(t *tileset.Tile) string {
+	if cm.Find(t.Chr, t.Start, t.End) == nil {
+		return "not contained by any band"
+	}
+	return ""
+}
+
+// AddTiles adds tiles to rules that contain them, and returns the number of
+// tiles that no rule is found.
+func (cm *CytoMap) AddTiles(tiles []*tileset.Tile) (missed int) {
+	for _, t := range tiles {
+		if !cm.checkRule(t.Chr, t.Start, t.End, t.Data) {
+			missed++
 		}
 	}
-	return false
+	return missed
 }
 
 func (cm *CytoMap) parseTile(i int) (n int64, err error) {
@@ -119,14 +221,7 @@ func (cm *CytoMap) parseTile(i int) (n int64, err error) {
 		}
 
 		// Get information about current tile.
-		infos := strings.Split(snr.Text(), ":")
-		idxes := strings.Split(infos[1], "-")
-		chr = infos[0][1:]
-		start, err = strconv.ParseInt(idxes[0], 10, 64)
-		if err != nil {
-			return 0, nil
-		}
-		end, err = strconv.ParseInt(idxes[1], 10, 64)
+		chr, start, end, err = tileset.ParseHeader(snr.Text())
 		if err != nil {
 			return 0, nil
 		}
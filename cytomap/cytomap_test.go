@@ -0,0 +1,112 @@
+package cytomap
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func TestFind(t *testing.T) {
+	Convey("Find rule that contains range", t, func() {
+		cm := &CytoMap{Hg: 19, Rules: []*CytoRule{
+			{Chr: "chr1", Start: 0, End: 100, Section: "p36.33"},
+			{Chr: "chr1", Start: 100, End: 200, Section: "p36.32"},
+			{Chr: "chr2", Start: 0, End: 100, Section: "p25.3"},
+		}}
+		find := func(chr string, start, end int64) string {
+			if rule := cm.Find(chr, start, end); rule != nil {
+				return rule.Section
+			}
+			return ""
+		}
+
+		for _, indexed := range []bool{false, true} {
+			if indexed {
+				cm.BuildIndex()
+			}
+			So(find("chr1", 10, 20), ShouldEqual, "p36.33")
+			So(find("chr1", 100, 200), ShouldEqual, "p36.32")
+			So(find("chr1", 90, 110), ShouldEqual, "")
+			So(find("chr2", 0, 0), ShouldEqual, "p25.3")
+			So(find("chr3", 0, 10), ShouldEqual, "")
+		}
+
+		Convey("Stale index is not used", func() {
+			cm.Rules = append(cm.Rules, &CytoRule{Chr: "chr3", Start: 0, End: 100, Section: "p26.3"})
+			So(find("chr3", 0, 10), ShouldEqual, "p26.3")
+		})
+	})
+}
+
+func TestAddTiles(t *testing.T) {
+	Convey("Add tiles to rules that contain them", t, func() {
+		cm := &CytoMap{Hg: 19, Rules: []*CytoRule{
+			{Chr: "chr1", Start: 0, End: 100, Section: "p36.33"},
+			{Chr: "chr1", Start: 100, End: 200, Section: "p36.32"},
+		}}
+		cm.BuildIndex()
+		So(cm.AddTiles([]*tileset.Tile{
+			{Chr: "chr1", Start: 0, End: 50, Data: []byte("A")},
+			{Chr: "chr1", Start: 50, End: 100},
+			{Chr: "chr1", Start: 90, End: 110},
+			{Chr: "chr1", Start: 110, End: 150},
+		}), ShouldEqual, 1)
+		So(len(cm.Rules[0].Tiles), ShouldEqual, 2)
+		So(string(cm.Rules[0].Tiles[0].Data), ShouldEqual, "A")
+		So(len(cm.Rules[1].Tiles), ShouldEqual, 1)
+	})
+}
+
+func TestAssembly(t *testing.T) {
+	Convey("Look up assemblies", t, func() {
+		So(LookupAssembly("hg19"), ShouldEqual, HG19)
+		So(LookupAssembly("GRCh38"), ShouldEqual, HG38)
+		So(LookupAssembly("grch38"), ShouldEqual, HG38)
+		So(LookupAssembly("18"), ShouldEqual, HG18)
+		So(*LookupAssembly("panTro4"), ShouldResemble, Assembly{Name: "panTro4"})
+		So(AssemblyOf(38), ShouldEqual, HG38)
+		So(AssemblyOf(17).String(), ShouldEqual, "hg17")
+	})
+
+	Convey("Normalize chromosome names", t, func() {
+		for _, c := range [][2]string{
+			{"1", "chr1"}, {"chr1", "chr1"}, {"Chr22", "chr22"}, {"X", "chrX"}, {"chrx", "chrX"},
+			{"MT", "chrM"}, {"chrM", "chrM"}, {"chrMT", "chrM"}, {"M", "chrM"},
+			{"GL000192.1", "GL000192.1"}, {"chr1_gl000191_random", "chr1_gl000191_random"},
+			{"0", "0"}, {"", ""}, {"chr", "chr"},
+		} {
+			So(NormalizeChr(c[0]), ShouldEqual, c[1])
+		}
+	})
+
+	Convey("Sort chromosomes in natural order", t, func() {
+		chrs := []string{"chr10", "chrM", "chrX", "GL000192.1", "2", "chr1", "chrY"}
+		SortChrs(chrs)
+		So(chrs, ShouldResemble, []string{"chr1", "2", "chr10", "chrX", "chrY", "chrM", "GL000192.1"})
+	})
+
+	Convey("Find rules with different chromosome names", t, func() {
+		cm := &CytoMap{Hg: 19, Rules: []*CytoRule{
+			{Chr: "chr1", Start: 0, End: 100, Section: "p36.33"},
+			{Chr: "chrM", Start: 0, End: 100, Section: "m"},
+		}}
+		So(cm.Find("1", 10, 20), ShouldEqual, cm.Rules[0])
+		So(cm.FindBand("MT", "m"), ShouldEqual, cm.Rules[1])
+		cm.BuildIndex()
+		So(cm.Find("MT", 10, 20), ShouldEqual, cm.Rules[1])
+	})
+}
+
+func TestCheckTile(t *testing.T) {
+	Convey("Check tiles are contained by bands", t, func() {
+		cm := &CytoMap{Hg: 19, Rules: []*CytoRule{
+			{Chr: "chr1", Start: 0, End: 100, Section: "p36.33"},
+			{Chr: "chr1", Start: 100, End: 200, Section: "p36.32"},
+		}}
+		So(cm.CheckTile(&tileset.Tile{Chr: "chr1", Start: 10, End: 20}), ShouldEqual, "")
+		So(cm.CheckTile(&tileset.Tile{Chr: "chr1", Start: 90, End: 120}), ShouldNotBeEmpty)
+		So(cm.CheckTile(&tileset.Tile{Chr: "chr2", Start: 10, End: 20}), ShouldNotBeEmpty)
+	})
+}
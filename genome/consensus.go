@@ -0,0 +1,49 @@
+package genome
+
+import "errors"
+
+// ErrLengthMismatch is returned when sequences have different numbers of blocks.
+var ErrLengthMismatch = errors.New("genome: sequences have different numbers of blocks")
+
+// Consensus builds consensus sequence of a cohort by picking the most common
+// variant of every tile, ties go to the variant that appears first.
+// Invalid blocks and blocks with mixed tags are not counted, and the block of
+// consensus is invalid when no sequence has a countable block for the tile.
+func Consensus(seqs []*Sequence) (*Sequence, error) {
+	if len(seqs) == 0 {
+		return &Sequence{}, nil
+	}
+	n := seqs[0].Length()
+	for _, s := range seqs[1:] {
+		if s.Length() != n {
+			return nil, ErrLengthMismatch
+		}
+	}
+
+	cs := &Sequence{Blocks: make([]*Block, n)}
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		for _, s := range seqs {
+			if b := s.Blocks[i]; b.Valid && b.NumMixedTag == 0 {
+				counts[string(b.Data)]++
+			}
+		}
+
+		var (
+			best  string
+			count int
+		)
+		// Check in order of sequences so ties go to the variant that appears first.
+		for _, s := range seqs {
+			if b := s.Blocks[i]; b.Valid && b.NumMixedTag == 0 && counts[string(b.Data)] > count {
+				best, count = string(b.Data), counts[string(b.Data)]
+			}
+		}
+		for k := range counts {
+			delete(counts, k)
+		}
+
+		cs.Blocks[i] = &Block{Valid: count > 0, Data: []byte(best)}
+	}
+	return cs, nil
+}
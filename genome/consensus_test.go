@@ -0,0 +1,39 @@
+package genome
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConsensus(t *testing.T) {
+	Convey("Build consensus sequence of a cohort", t, func() {
+		newSeq := func(data ...string) *Sequence {
+			s := &Sequence{}
+			for _, d := range data {
+				s.Blocks = append(s.Blocks, &Block{Valid: d != "N", Data: []byte(d)})
+			}
+			return s
+		}
+
+		seqs := []*Sequence{
+			newSeq("AC", "GT", "N", "TA"),
+			newSeq("AA", "GA", "N", "TT"),
+			newSeq("AA", "GT", "N", "TA"),
+			newSeq("AC", "GA", "N", "N"),
+		}
+		seqs[3].Blocks[1].NumMixedTag = 1
+
+		cs, err := Consensus(seqs)
+		So(err, ShouldBeNil)
+		So(cs, ShouldResemble, &Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("AC")},
+			{Valid: true, Data: []byte("GT")},
+			{Valid: false, Data: []byte{}},
+			{Valid: true, Data: []byte("TA")},
+		}})
+
+		_, err = Consensus([]*Sequence{newSeq("AC"), newSeq("AC", "GT")})
+		So(err, ShouldEqual, ErrLengthMismatch)
+	})
+}
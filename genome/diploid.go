@@ -0,0 +1,221 @@
+package genome
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrUnphased is returned when phase flags do not match number of tiles.
+var ErrUnphased = errors.New("genome: phase flags do not match number of tiles")
+
+// Diploid represents diploid genome sequence with two haplotypes of blocks,
+// blocks at the same position of two haplotypes are the same tile.
+type Diploid struct {
+	Haplotypes [2]*Sequence
+	Phased     []bool // Whether the pair of blocks of tile is phased.
+}
+
+// NewDiploid returns a new diploid sequence of two haplotypes,
+// all tiles are marked by given phased flag.
+func NewDiploid(a, b *Sequence, phased bool) (*Diploid, error) {
+	if a.Length() != b.Length() {
+		return nil, ErrLengthMismatch
+	}
+	d := &Diploid{
+		Haplotypes: [2]*Sequence{a, b},
+		Phased:     make([]bool, a.Length()),
+	}
+	for i := range d.Phased {
+		d.Phased[i] = phased
+	}
+	return d, nil
+}
+
+// Length returns the number of tiles in the sequence.
+func (d *Diploid) Length() int {
+	return d.Haplotypes[0].Length()
+}
+
+// check returns error when haplotypes or phase flags do not match.
+func (d *Diploid) check() error {
+	if d.Haplotypes[0].Length() != d.Haplotypes[1].Length() {
+		return ErrLengthMismatch
+	} else if len(d.Phased) != d.Length() {
+		return ErrUnphased
+	}
+	return nil
+}
+
+// Write writes diploid sequence in tiled genome format, every tile is a record
+// with header ">index phased valid1 numMixedTag1 valid2 numMixedTag2" and data
+// of two haplotypes in two lines, phased and valids are 0 or 1. Quality of
+// blocks follows as fields of Sequence.Write whose keys end with 1 or 2.
+func (d *Diploid) Write(w io.Writer) error {
+	if err := d.check(); err != nil {
+		return err
+	}
+
+	itoa := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	bw := bufio.NewWriter(w)
+	for i, phased := range d.Phased {
+		a, b := d.Haplotypes[0].Blocks[i], d.Haplotypes[1].Blocks[i]
+		fmt.Fprintf(bw, ">%d %d %d %d %d %d", i, itoa(phased),
+			itoa(a.Valid), a.NumMixedTag, itoa(b.Valid), b.NumMixedTag)
+		writeQuality(bw, a, "1")
+		writeQuality(bw, b, "2")
+		bw.WriteString("\n")
+		bw.Write(a.Data)
+		bw.WriteString("\n")
+		bw.Write(b.Data)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// ReadDiploid reads diploid sequence in tiled genome format that is written by Write.
+func ReadDiploid(r io.Reader) (*Diploid, error) {
+	d := &Diploid{Haplotypes: [2]*Sequence{{}, {}}}
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<30)
+	// Number of data lines that are expected of current tile.
+	rest := 0
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if rest > 0 {
+			if strings.HasPrefix(text, ">") {
+				return nil, fmt.Errorf("genome: line %d: missing haplotype data", line)
+			}
+			b := d.Haplotypes[2-rest].Blocks[len(d.Phased)-1]
+			b.Data = append(b.Data, text...)
+			rest--
+			continue
+		}
+		if !strings.HasPrefix(text, ">") {
+			return nil, fmt.Errorf("genome: line %d: data without header", line)
+		}
+
+		infos := strings.Fields(text[1:])
+		if len(infos) < 6 {
+			return nil, fmt.Errorf("genome: line %d: invalid header: %s", line, text)
+		}
+		idx, err := strconv.Atoi(infos[0])
+		if err != nil || idx != len(d.Phased) {
+			return nil, fmt.Errorf("genome: line %d: unexpected index: %s", line, infos[0])
+		}
+		d.Phased = append(d.Phased, infos[1] == "1")
+
+		// Fields of quality of haplotypes by suffixes of keys.
+		var fields [2][]string
+		for _, field := range infos[6:] {
+			i := strings.IndexByte(field, '=')
+			if i < 1 || (field[i-1] != '1' && field[i-1] != '2') {
+				return nil, fmt.Errorf("genome: line %d: invalid field: %s", line, field)
+			}
+			h := field[i-1] - '1'
+			fields[h] = append(fields[h], field[:i-1]+field[i:])
+		}
+		for i := 0; i < 2; i++ {
+			b := &Block{Valid: infos[2+i*2] == "1", Data: []byte{}}
+			if b.NumMixedTag, err = strconv.Atoi(infos[3+i*2]); err != nil {
+				return nil, fmt.Errorf("genome: line %d: %v", line, err)
+			}
+			if err = parseQuality(b, fields[i]); err != nil {
+				return nil, fmt.Errorf("genome: line %d: %v", line, err)
+			}
+			d.Haplotypes[i].Blocks = append(d.Haplotypes[i].Blocks, b)
+		}
+		rest = 2
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+	if rest > 0 {
+		return nil, errors.New("genome: missing haplotype data at the end")
+	}
+	return d, nil
+}
+
+// Variants numbers variants of tiles, number 1 is the reference variant
+// and other variants are numbered from 2 in the order they are seen.
+type Variants struct {
+	tiles []map[string]int
+}
+
+// NewVariants returns a new variant numbering for tiles of reference sequence.
+func NewVariants(ref *Sequence) *Variants {
+	v := &Variants{tiles: make([]map[string]int, ref.Length())}
+	for i, b := range ref.Blocks {
+		v.tiles[i] = map[string]int{string(b.Data): 1}
+	}
+	return v
+}
+
+// Number returns number of variant of tile i, new number is given when the
+// variant has not been seen, it returns 0 when tile i is out of range.
+func (v *Variants) Number(i int, data []byte) int {
+	if i < 0 || i >= len(v.tiles) {
+		return 0
+	}
+	num, ok := v.tiles[i][string(data)]
+	if !ok {
+		num = len(v.tiles[i]) + 1
+		v.tiles[i][string(data)] = num
+	}
+	return num
+}
+
+// Match returns the smallest number of variant of tile i that agrees with
+// called bases of block, it returns 0 when no variant agrees. Bases that are
+// masked in block or variants are not compared.
+func (v *Variants) Match(i int, b *Block) int {
+	if i < 0 || i >= len(v.tiles) {
+		return 0
+	}
+	if len(b.NoCalls) == 0 && bytes.IndexByte(b.Data, 'N') == -1 {
+		if num, ok := v.tiles[i][string(b.Data)]; ok {
+			return num
+		}
+	}
+	best := 0
+	for data, num := range v.tiles[i] {
+		if best > 0 && num > best {
+			continue
+		}
+		if equal, n := CompareCalled(b, &Block{Data: []byte(data)}); equal && n > 0 {
+			best = num
+		}
+	}
+	return best
+}
+
+// Data returns data of variant num of tile i, it returns nil when the variant
+// has not been seen.
+func (v *Variants) Data(i, num int) []byte {
+	if i < 0 || i >= len(v.tiles) {
+		return nil
+	}
+	for data, n := range v.tiles[i] {
+		if n == num {
+			return []byte(data)
+		}
+	}
+	return nil
+}
+
+// Count returns the number of variants of tile i that have been seen.
+func (v *Variants) Count(i int) int {
+	if i < 0 || i >= len(v.tiles) {
+		return 0
+	}
+	return len(v.tiles[i])
+}
@@ -0,0 +1,93 @@
+package genome
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiploid(t *testing.T) {
+	Convey("Write and read diploid sequence in tiled genome format", t, func() {
+		a := &Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("ACGT")},
+			{Valid: false, NumMixedTag: 2, Data: []byte("ANNT")},
+		}}
+		b := &Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("ACGA")},
+			{Valid: true, Data: []byte("AGGT")},
+		}}
+		d, err := NewDiploid(a, b, true)
+		So(err, ShouldBeNil)
+		So(d.Length(), ShouldEqual, 2)
+		d.Phased[1] = false
+
+		var buf bytes.Buffer
+		So(d.Write(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual, ">0 1 1 0 1 0\nACGT\nACGA\n>1 0 0 2 1 0\nANNT\nAGGT\n")
+
+		d2, err := ReadDiploid(&buf)
+		So(err, ShouldBeNil)
+		So(d2, ShouldResemble, d)
+
+		_, err = NewDiploid(a, &Sequence{}, false)
+		So(err, ShouldEqual, ErrLengthMismatch)
+
+		d.Phased = d.Phased[:1]
+		So(d.Write(&buf), ShouldEqual, ErrUnphased)
+
+		_, err = ReadDiploid(strings.NewReader(">0 1 1 0 1 0\nACGT\n>1 1 1 0 1 0\nA\nA\n"))
+		So(err, ShouldNotBeNil)
+		_, err = ReadDiploid(strings.NewReader(">0 1 1 0 1 0\nACGT\n"))
+		So(err, ShouldNotBeNil)
+
+		// Quality of blocks of both haplotypes.
+		a.Blocks[0].NoCalls = []Range{{Start: 2, End: 4}}
+		a.Blocks[1].Reason = IR_NO_CALL
+		b.Blocks[1].Depth, b.Blocks[1].Quality = 12.5, 30
+		d, err = NewDiploid(a, b, true)
+		So(err, ShouldBeNil)
+		buf.Reset()
+		So(d.Write(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual, ">0 1 1 0 1 0 nocall1=2-4\nACGT\nACGA\n"+
+			">1 1 0 2 1 0 reason1=no-call depth2=12.5 qual2=30\nANNT\nAGGT\n")
+		d2, err = ReadDiploid(&buf)
+		So(err, ShouldBeNil)
+		So(d2, ShouldResemble, d)
+
+		_, err = ReadDiploid(strings.NewReader(">0 1 1 0 1 0 nocall=2-4\nACGT\nACGA\n"))
+		So(err, ShouldNotBeNil)
+		_, err = ReadDiploid(strings.NewReader(">0 1 1 0 1 0 bad1=2\nACGT\nACGA\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestVariants(t *testing.T) {
+	Convey("Number variants of tiles", t, func() {
+		vs := NewVariants(&Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("AC")},
+			{Valid: true, Data: []byte("GT")},
+		}})
+		So(vs.Number(0, []byte("AC")), ShouldEqual, 1)
+		So(vs.Number(0, []byte("AA")), ShouldEqual, 2)
+		So(vs.Number(0, []byte("CC")), ShouldEqual, 3)
+		So(vs.Number(0, []byte("AA")), ShouldEqual, 2)
+		So(vs.Count(0), ShouldEqual, 3)
+		So(vs.Number(1, []byte("GT")), ShouldEqual, 1)
+		So(vs.Number(2, []byte("GT")), ShouldEqual, 0)
+		So(vs.Count(2), ShouldEqual, 0)
+
+		So(string(vs.Data(0, 3)), ShouldEqual, "CC")
+		So(string(vs.Data(1, 1)), ShouldEqual, "GT")
+		So(vs.Data(1, 2), ShouldBeNil)
+		So(vs.Data(2, 1), ShouldBeNil)
+
+		So(vs.Match(0, &Block{Data: []byte("CC")}), ShouldEqual, 3)
+		So(vs.Match(0, &Block{Data: []byte("AN")}), ShouldEqual, 1)
+		So(vs.Match(0, &Block{Data: []byte("AC"), NoCalls: []Range{{Start: 1, End: 2}}}), ShouldEqual, 1)
+		So(vs.Match(0, &Block{Data: []byte("NN")}), ShouldEqual, 0)
+		So(vs.Match(0, &Block{Data: []byte("GG")}), ShouldEqual, 0)
+		So(vs.Match(2, &Block{Data: []byte("GT")}), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,170 @@
+package genome
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Write writes sequence in tiled genome format, every block is a record
+// with header ">index valid numMixedTag", valid is 0 or 1. Known quality of
+// block follows in "key=value" fields: depth, qual, nocall and reason.
+func (s *Sequence) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, b := range s.Blocks {
+		valid := 0
+		if b.Valid {
+			valid = 1
+		}
+		fmt.Fprintf(bw, ">%d %d %d", i, valid, b.NumMixedTag)
+		writeQuality(bw, b, "")
+		bw.WriteString("\n")
+		bw.Write(b.Data)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// Read reads sequence in tiled genome format that is written by Write.
+func Read(r io.Reader) (*Sequence, error) {
+	s := &Sequence{}
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<30)
+	var b *Block
+	for line := 1; snr.Scan(); line++ {
+		text := snr.Text()
+		if !strings.HasPrefix(text, ">") {
+			if b == nil {
+				return nil, fmt.Errorf("genome: line %d: data without header", line)
+			}
+			b.Data = append(b.Data, text...)
+			continue
+		}
+
+		infos := strings.Fields(text[1:])
+		if len(infos) < 3 {
+			return nil, fmt.Errorf("genome: line %d: invalid header: %s", line, text)
+		}
+		idx, err := strconv.Atoi(infos[0])
+		if err != nil || idx != len(s.Blocks) {
+			return nil, fmt.Errorf("genome: line %d: unexpected index: %s", line, infos[0])
+		}
+		b = &Block{Valid: infos[1] == "1", Data: []byte{}}
+		if b.NumMixedTag, err = strconv.Atoi(infos[2]); err != nil {
+			return nil, fmt.Errorf("genome: line %d: %v", line, err)
+		}
+		if err = parseQuality(b, infos[3:]); err != nil {
+			return nil, fmt.Errorf("genome: line %d: %v", line, err)
+		}
+		s.Blocks = append(s.Blocks, b)
+	}
+	return s, snr.Err()
+}
+
+// writeQuality writes " key=value" fields of quality of block that are known,
+// suffix is appended to keys.
+func writeQuality(bw *bufio.Writer, b *Block, suffix string) {
+	if b.Depth > 0 {
+		bw.WriteString(" depth" + suffix + "=" + strconv.FormatFloat(b.Depth, 'f', -1, 64))
+	}
+	if b.Quality > 0 {
+		bw.WriteString(" qual" + suffix + "=" + strconv.Itoa(b.Quality))
+	}
+	if len(b.NoCalls) > 0 {
+		bw.WriteString(" nocall" + suffix + "=" + formatRanges(b.NoCalls))
+	}
+	if b.Reason != IR_NONE {
+		bw.WriteString(" reason" + suffix + "=" + b.Reason.String())
+	}
+}
+
+// parseQuality parses "key=value" fields of quality of block.
+func parseQuality(b *Block, fields []string) error {
+	for _, field := range fields {
+		i := strings.IndexByte(field, '=')
+		if i == -1 {
+			return fmt.Errorf("invalid field: %s", field)
+		}
+		key, value := field[:i], field[i+1:]
+		var err error
+		switch key {
+		case "depth":
+			b.Depth, err = strconv.ParseFloat(value, 64)
+		case "qual":
+			b.Quality, err = strconv.Atoi(value)
+		case "nocall":
+			b.NoCalls, err = parseRanges(value)
+		case "reason":
+			b.Reason, err = ParseReason(value)
+		default:
+			return fmt.Errorf("unknown field: %s", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFASTA reads sequences of FASTA format data by names,
+// name is the first word of header.
+func ReadFASTA(r io.Reader) (map[string][]byte, error) {
+	seqs := make(map[string][]byte)
+	var (
+		name string
+		buf  bytes.Buffer
+	)
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<30)
+	for snr.Scan() {
+		byts := snr.Bytes()
+		if len(byts) == 0 || byts[0] == ';' {
+			continue
+		}
+		if byts[0] != '>' {
+			buf.Write(bytes.TrimSpace(byts))
+			continue
+		}
+
+		if len(name) > 0 {
+			seqs[name] = append([]byte(nil), buf.Bytes()...)
+			buf.Reset()
+		}
+		if fields := strings.Fields(string(byts[1:])); len(fields) > 0 {
+			name = fields[0]
+		} else {
+			return nil, fmt.Errorf("genome: empty FASTA header")
+		}
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+	if len(name) > 0 {
+		seqs[name] = append([]byte(nil), buf.Bytes()...)
+	}
+	return seqs, nil
+}
+
+// Tile cuts sequences that are in reference coordinates into blocks by tiles.
+// Blocks that are out of range are invalid, bases that are 'N' are masked and
+// so are lower case bases when softMask is true. Data of blocks is in upper case.
+func Tile(seqs map[string][]byte, tiles []*tileset.Tile, softMask bool) *Sequence {
+	s := &Sequence{Blocks: make([]*Block, len(tiles))}
+	for i, t := range tiles {
+		b := &Block{Reason: IR_MISSING}
+		s.Blocks[i] = b
+		seq, ok := seqs[t.Chr]
+		if !ok || t.Start < 0 || t.End > int64(len(seq)) || t.Start > t.End {
+			continue
+		}
+		b.NoCalls = maskedRanges(seq[t.Start:t.End], softMask)
+		b.Data = bytes.ToUpper(seq[t.Start:t.End])
+		b.Valid, b.Reason = true, IR_NONE
+	}
+	return s
+}
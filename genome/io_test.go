@@ -0,0 +1,87 @@
+package genome
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func TestWrite(t *testing.T) {
+	Convey("Write and read sequence in tiled genome format", t, func() {
+		s := &Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("ACGT")},
+			{Valid: false, NumMixedTag: 2, Data: []byte("ANNT")},
+			{Valid: true, Data: []byte{}},
+		}}
+		var buf bytes.Buffer
+		So(s.Write(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual, ">0 1 0\nACGT\n>1 0 2\nANNT\n>2 1 0\n\n")
+
+		s2, err := Read(&buf)
+		So(err, ShouldBeNil)
+		So(s2, ShouldResemble, s)
+
+		_, err = Read(strings.NewReader(">1 1 0\nACGT\n"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Write and read sequence with quality of blocks", t, func() {
+		s := &Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("ACGT"), Depth: 12.5, Quality: 30},
+			{Valid: false, Data: []byte("ANNT"), NoCalls: []Range{{1, 3}}, Reason: IR_NO_CALL},
+		}}
+		var buf bytes.Buffer
+		So(s.Write(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual,
+			">0 1 0 depth=12.5 qual=30\nACGT\n>1 0 0 nocall=1-3 reason=no-call\nANNT\n")
+
+		s2, err := Read(&buf)
+		So(err, ShouldBeNil)
+		So(s2, ShouldResemble, s)
+
+		for _, header := range []string{
+			">0 1 0 depth\n", ">0 1 0 size=1\n", ">0 1 0 qual=x\n",
+			">0 1 0 nocall=3-1\n", ">0 1 0 reason=bad\n",
+		} {
+			_, err = Read(strings.NewReader(header))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestTile(t *testing.T) {
+	Convey("Cut FASTA sequences into blocks by tiles", t, func() {
+		seqs, err := ReadFASTA(strings.NewReader(">chr1 test\nACgtAC\nGTnA\n>chr2\nTTTT\n"))
+		So(err, ShouldBeNil)
+		So(string(seqs["chr1"]), ShouldEqual, "ACgtACGTnA")
+
+		tiles := []*tileset.Tile{
+			{Chr: "chr1", Start: 0, End: 4},
+			{Chr: "chr1", Start: 6, End: 10},
+			{Chr: "chr2", Start: 2, End: 8},
+			{Chr: "chr3", Start: 0, End: 1},
+		}
+		s := Tile(seqs, tiles, true)
+		So(s.Length(), ShouldEqual, 4)
+		So(string(s.Blocks[0].Data), ShouldEqual, "ACGT")
+		So(s.Blocks[0].Valid, ShouldBeTrue)
+		So(s.Blocks[0].NoCalls, ShouldResemble, []Range{{2, 4}})
+		So(string(s.Blocks[1].Data), ShouldEqual, "GTNA")
+		So(s.Blocks[1].Valid, ShouldBeTrue)
+		So(s.Blocks[1].NoCalls, ShouldResemble, []Range{{2, 3}})
+		So(s.Blocks[1].Reason, ShouldEqual, IR_NONE)
+		So(s.Blocks[2].Valid, ShouldBeFalse)
+		So(s.Blocks[2].Reason, ShouldEqual, IR_MISSING)
+		So(s.Blocks[3].Valid, ShouldBeFalse)
+
+		// Soft-masked bases are called by default.
+		s = Tile(seqs, tiles, false)
+		So(string(s.Blocks[0].Data), ShouldEqual, "ACGT")
+		So(s.Blocks[0].NoCalls, ShouldBeNil)
+		So(s.Blocks[1].NoCalls, ShouldResemble, []Range{{2, 3}})
+	})
+}
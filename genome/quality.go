@@ -0,0 +1,193 @@
+package genome
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Reason represents the reason why a block is invalid.
+type Reason int
+
+const (
+	IR_NONE        Reason = iota
+	IR_MISSING            // No data of tile, e.g. out of range of sequences.
+	IR_NO_CALL            // Too many bases are not called.
+	IR_LOW_DEPTH          // Read depth is too low.
+	IR_LOW_QUALITY        // Quality of call is too low.
+)
+
+var reasonNames = [...]string{"none", "missing", "no-call", "low-depth", "low-quality"}
+
+// String returns name of Reason in lower case.
+func (r Reason) String() string {
+	if r < 0 || int(r) >= len(reasonNames) {
+		return "Reason(" + strconv.Itoa(int(r)) + ")"
+	}
+	return reasonNames[r]
+}
+
+// ParseReason returns Reason by name that is returned by String.
+func ParseReason(name string) (Reason, error) {
+	for i, n := range reasonNames {
+		if n == name {
+			return Reason(i), nil
+		}
+	}
+	return 0, fmt.Errorf("genome: unknown reason %q", name)
+}
+
+// Range represents positions [Start, End) of bases in a block.
+type Range struct {
+	Start, End int
+}
+
+// NoCallRanges returns ranges of bases in data that are not called ('N').
+func NoCallRanges(data []byte) []Range {
+	return maskedRanges(data, false)
+}
+
+// maskedRanges returns ranges of bases in data that are not called, and also
+// soft-masked (lower case) when softMask is true.
+func maskedRanges(data []byte, softMask bool) []Range {
+	var rs []Range
+	for i := 0; i < len(data); i++ {
+		if data[i] != 'N' && data[i] != 'n' && (!softMask || data[i] < 'a' || data[i] > 'z') {
+			continue
+		}
+		if n := len(rs); n > 0 && rs[n-1].End == i {
+			rs[n-1].End++
+		} else {
+			rs = append(rs, Range{i, i + 1})
+		}
+	}
+	return rs
+}
+
+// NumNoCalls returns the number of bases that are not called, in the same
+// way as Called.
+func (b *Block) NumNoCalls() int {
+	n := 0
+	for _, c := range b.Called() {
+		if !c {
+			n++
+		}
+	}
+	return n
+}
+
+// Called returns mask of bases of block that are called, bases that are 'N'
+// or in NoCalls are not called.
+func (b *Block) Called() []bool {
+	mask := make([]bool, len(b.Data))
+	for i, c := range b.Data {
+		mask[i] = c != 'N' && c != 'n'
+	}
+	for _, r := range b.NoCalls {
+		for i := r.Start; i < r.End && i < len(mask); i++ {
+			mask[i] = false
+		}
+	}
+	return mask
+}
+
+// CompareCalled compares bases of blocks that are called in both of them, and
+// returns whether they agree and the number of bases that are compared.
+// Blocks of different lengths do not agree and no base is compared.
+func CompareCalled(a, b *Block) (bool, int) {
+	if len(a.Data) != len(b.Data) {
+		return false, 0
+	}
+	if len(a.NoCalls) == 0 && len(b.NoCalls) == 0 &&
+		bytes.IndexByte(a.Data, 'N') == -1 && bytes.IndexByte(b.Data, 'N') == -1 {
+		return bytes.Equal(a.Data, b.Data), len(a.Data)
+	}
+
+	ma, mb := a.Called(), b.Called()
+	equal, n := true, 0
+	for i := range ma {
+		if ma[i] && mb[i] {
+			n++
+			equal = equal && a.Data[i] == b.Data[i]
+		}
+	}
+	return equal, n
+}
+
+// formatRanges formats ranges as comma-separated list of "start-end".
+func formatRanges(rs []Range) string {
+	strs := make([]string, len(rs))
+	for i, r := range rs {
+		strs[i] = strconv.Itoa(r.Start) + "-" + strconv.Itoa(r.End)
+	}
+	return strings.Join(strs, ",")
+}
+
+// parseRanges parses ranges that are formatted by formatRanges.
+func parseRanges(str string) ([]Range, error) {
+	var rs []Range
+	for _, s := range strings.Split(str, ",") {
+		i := strings.IndexByte(s, '-')
+		if i == -1 {
+			return nil, fmt.Errorf("invalid range: %s", s)
+		}
+		start, err1 := strconv.Atoi(s[:i])
+		end, err2 := strconv.Atoi(s[i+1:])
+		if err1 != nil || err2 != nil || start < 0 || start >= end {
+			return nil, fmt.Errorf("invalid range: %s", s)
+		}
+		rs = append(rs, Range{start, end})
+	}
+	return rs, nil
+}
+
+// Thresholds represents requirements of quality of valid blocks, zero values
+// disable the checks. Values of quality that are unknown, i.e. zero, pass.
+type Thresholds struct {
+	MinDepth          float64
+	MinQuality        int
+	MaxNoCalls        int     // Maximum number of bases that are masked.
+	MaxNoCallFraction float64 // Maximum fraction of bases that are masked.
+}
+
+// Check returns the reason why block does not meet thresholds,
+// or IR_NONE when it does.
+func (th Thresholds) Check(b *Block) Reason {
+	switch {
+	case !b.Valid:
+		if b.Reason == IR_NONE {
+			return IR_MISSING
+		}
+		return b.Reason
+	case th.MinDepth > 0 && b.Depth > 0 && b.Depth < th.MinDepth:
+		return IR_LOW_DEPTH
+	case th.MinQuality > 0 && b.Quality > 0 && b.Quality < th.MinQuality:
+		return IR_LOW_QUALITY
+	case th.MaxNoCalls > 0 && b.NumNoCalls() > th.MaxNoCalls:
+		return IR_NO_CALL
+	case th.MaxNoCallFraction > 0 && len(b.Data) > 0 &&
+		float64(b.NumNoCalls()) > th.MaxNoCallFraction*float64(len(b.Data)):
+		return IR_NO_CALL
+	}
+	return IR_NONE
+}
+
+// Filter returns a copy of sequence that blocks which do not meet thresholds
+// are invalid, so they are DT_UNKNOWN in diffing. Blocks are shared when
+// they are not changed.
+func (th Thresholds) Filter(s *Sequence) *Sequence {
+	fs := &Sequence{Blocks: make([]*Block, len(s.Blocks))}
+	for i, b := range s.Blocks {
+		fs.Blocks[i] = b
+		if !b.Valid {
+			continue
+		}
+		if r := th.Check(b); r != IR_NONE {
+			fb := *b
+			fb.Valid, fb.Reason = false, r
+			fs.Blocks[i] = &fb
+		}
+	}
+	return fs
+}
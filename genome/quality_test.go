@@ -0,0 +1,99 @@
+package genome
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReason(t *testing.T) {
+	Convey("Names of reasons", t, func() {
+		So(IR_LOW_DEPTH.String(), ShouldEqual, "low-depth")
+		So(Reason(10).String(), ShouldEqual, "Reason(10)")
+		r, err := ParseReason("no-call")
+		So(err, ShouldBeNil)
+		So(r, ShouldEqual, IR_NO_CALL)
+		_, err = ParseReason("bad")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestNoCallRanges(t *testing.T) {
+	Convey("Find ranges of no-call bases", t, func() {
+		So(NoCallRanges([]byte("ACGT")), ShouldBeNil)
+		rs := NoCallRanges([]byte("NNACnTN"))
+		So(rs, ShouldResemble, []Range{{0, 2}, {4, 5}, {6, 7}})
+		So((&Block{Data: []byte("NNACnTN"), NoCalls: rs}).NumNoCalls(), ShouldEqual, 4)
+		// Bases that are 'N' are not called without ranges.
+		So((&Block{Data: []byte("ANNN")}).NumNoCalls(), ShouldEqual, 3)
+		So((&Block{Data: []byte("ANNT"), NoCalls: []Range{{Start: 2, End: 4}}}).NumNoCalls(), ShouldEqual, 3)
+		So(NoCallRanges([]byte("ACgtnA")), ShouldResemble, []Range{{4, 5}})
+		So(maskedRanges([]byte("ACgtnA"), true), ShouldResemble, []Range{{2, 5}})
+	})
+}
+
+func TestCompareCalled(t *testing.T) {
+	Convey("Compare called bases of blocks", t, func() {
+		a := &Block{Data: []byte("ACGTAC")}
+		equal, n := CompareCalled(a, &Block{Data: []byte("ACGTAC")})
+		So(equal, ShouldBeTrue)
+		So(n, ShouldEqual, 6)
+		equal, n = CompareCalled(a, &Block{Data: []byte("ACGTAA")})
+		So(equal, ShouldBeFalse)
+		So(n, ShouldEqual, 6)
+
+		// Masked by 'N' or ranges.
+		equal, n = CompareCalled(a, &Block{Data: []byte("ANGTAA"), NoCalls: []Range{{5, 6}}})
+		So(equal, ShouldBeTrue)
+		So(n, ShouldEqual, 4)
+		equal, n = CompareCalled(&Block{Data: []byte("TTGTAC"), NoCalls: []Range{{0, 2}}}, &Block{Data: []byte("ACGTNN")})
+		So(equal, ShouldBeTrue)
+		So(n, ShouldEqual, 2)
+		equal, n = CompareCalled(&Block{Data: []byte("ACGTAC"), NoCalls: []Range{{0, 3}}}, &Block{Data: []byte("ACGNNN")})
+		So(n, ShouldEqual, 0)
+
+		equal, n = CompareCalled(a, &Block{Data: []byte("ACGTA")})
+		So(equal, ShouldBeFalse)
+		So(n, ShouldEqual, 0)
+	})
+}
+
+func TestThresholds(t *testing.T) {
+	Convey("Filter blocks by thresholds of quality", t, func() {
+		s := &Sequence{Blocks: []*Block{
+			{Valid: true, Data: []byte("ACGT")},
+			{Valid: true, Data: []byte("ACGT"), Depth: 5, Quality: 40},
+			{Valid: true, Data: []byte("ACGT"), Depth: 20, Quality: 10},
+			{Valid: true, Data: []byte("ACGT"), Depth: 20, Quality: 40, NoCalls: []Range{{0, 2}}},
+			{Valid: false, Reason: IR_MISSING},
+			{Valid: false},
+		}}
+
+		th := Thresholds{}
+		for _, b := range s.Blocks[:4] {
+			So(th.Check(b), ShouldEqual, IR_NONE)
+		}
+		So(th.Check(s.Blocks[5]), ShouldEqual, IR_MISSING)
+
+		th = Thresholds{MaxNoCallFraction: 0.5}
+		So(th.Check(s.Blocks[3]), ShouldEqual, IR_NONE)
+		th.MaxNoCallFraction = 0.4
+		So(th.Check(s.Blocks[3]), ShouldEqual, IR_NO_CALL)
+		// Bases that are 'N' count as masked.
+		th.MaxNoCallFraction = 0.5
+		So(th.Check(&Block{Valid: true, Data: []byte("ANNN")}), ShouldEqual, IR_NO_CALL)
+
+		th = Thresholds{MinDepth: 10, MinQuality: 20, MaxNoCalls: 1}
+		fs := th.Filter(s)
+		So(fs.Length(), ShouldEqual, s.Length())
+		So(fs.Blocks[0], ShouldEqual, s.Blocks[0])
+		So(fs.Blocks[4], ShouldEqual, s.Blocks[4])
+		for i, r := range []Reason{IR_NONE, IR_LOW_DEPTH, IR_LOW_QUALITY, IR_NO_CALL, IR_MISSING, IR_NONE} {
+			So(fs.Blocks[i].Reason, ShouldEqual, r)
+		}
+		So(fs.Blocks[1].Valid, ShouldBeFalse)
+		So(string(fs.Blocks[1].Data), ShouldEqual, "ACGT")
+		// Original blocks are not changed.
+		So(s.Blocks[1].Valid, ShouldBeTrue)
+	})
+}
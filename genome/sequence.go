@@ -6,6 +6,12 @@ type Block struct {
 	Valid       bool
 	NumMixedTag int // Number of mixed tag(for complex DiffType).
 	Data        []byte
+
+	// Quality of call, zero values are unknown.
+	Depth   float64 // Mean read depth.
+	Quality int     // Phred-scaled quality.
+	NoCalls []Range // Ranges of bases that are masked.
+	Reason  Reason  // Reason why block is invalid.
 }
 
 // Sequence represents processed genome sequence.
@@ -0,0 +1,204 @@
+// Package ideogram renders chromosome ideograms of cytomap in SVG format
+// with heat tracks of per-region values.
+package ideogram
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/cytomap"
+)
+
+// ErrNoChromosome is returned when there is no chromosome to draw.
+var ErrNoChromosome = errors.New("ideogram: no chromosome to draw")
+
+// Layout sizes in pixels.
+const (
+	margin      = 10
+	labelWidth  = 50
+	titleHeight = 24
+	chrHeight   = 14
+	trackHeight = 8
+	rowGap      = 12
+	legendWidth = 120
+)
+
+// Options represents options of rendering.
+type Options struct {
+	Width int      // Width of figure in pixels.
+	Chrs  []string // Chromosomes in order, default is all chromosomes of cytomap.
+	Title string
+}
+
+// DefaultOptions is the default options of rendering.
+var DefaultOptions = Options{Width: 800}
+
+// StainColor returns color of Giemsa stain of UCSC cytoband in format "#rrggbb".
+func StainColor(stain string) string {
+	switch stain {
+	case "gneg":
+		return "#ffffff"
+	case "acen":
+		return "#d92f27"
+	case "gvar":
+		return "#dcdcdc"
+	case "stalk":
+		return "#647fa4"
+	case "gpos":
+		return "#000000"
+	}
+	if strings.HasPrefix(stain, "gpos") {
+		if n, err := strconv.Atoi(stain[4:]); err == nil && n >= 0 && n <= 100 {
+			v := 255 - 255*n/100
+			return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+		}
+	}
+	return "#ffffff"
+}
+
+// HeatColor returns color of normalized value in [0, 1] from light yellow
+// to dark red in format "#rrggbb".
+func HeatColor(t float64) string {
+	t = math.Max(0, math.Min(1, t))
+	lo, hi := [3]float64{255, 255, 204}, [3]float64{189, 0, 38}
+	var c [3]int
+	for i := range c {
+		c[i] = int(math.Round(lo[i] + (hi[i]-lo[i])*t))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// Render draws ideograms of chromosomes of cytomap with heat tracks below
+// every chromosome, colors of tracks are scaled by range of values. Chromosome
+// names are normalized, e.g. "1" and "chr1" are the same.
+func Render(w io.Writer, cm *cytomap.CytoMap, tracks []*Track, opt Options) error {
+	var chrs []string
+	for _, chr := range opt.Chrs {
+		chrs = append(chrs, cytomap.NormalizeChr(chr))
+	}
+	size := make(map[string]int64)
+	for _, rule := range cm.Rules {
+		chr := cytomap.NormalizeChr(rule.Chr)
+		if _, ok := size[chr]; !ok && len(opt.Chrs) == 0 {
+			chrs = append(chrs, chr)
+		}
+		if rule.End > size[chr] {
+			size[chr] = rule.End
+		}
+	}
+	if len(opt.Chrs) == 0 {
+		cytomap.SortChrs(chrs)
+	}
+	var maxSize int64
+	for _, chr := range chrs {
+		if size[chr] == 0 {
+			return fmt.Errorf("ideogram: unknown chromosome: %s", chr)
+		}
+		if size[chr] > maxSize {
+			maxSize = size[chr]
+		}
+	}
+	if len(chrs) == 0 {
+		return ErrNoChromosome
+	}
+	if opt.Width <= 0 {
+		opt.Width = DefaultOptions.Width
+	}
+
+	left := float64(margin + labelWidth)
+	scale := (float64(opt.Width) - left - margin) / float64(maxSize)
+	rowHeight := chrHeight + len(tracks)*(trackHeight+2) + rowGap
+	top := margin
+	if len(opt.Title) > 0 {
+		top += titleHeight
+	}
+	height := top + len(chrs)*rowHeight + len(tracks)*(trackHeight+rowGap) + margin
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n",
+		opt.Width, height, opt.Width, height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	if len(opt.Title) > 0 {
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="14">%s</text>`+"\n", margin, margin+14, escape(opt.Title))
+	}
+
+	x := func(pos int64) float64 { return left + float64(pos)*scale }
+	width := func(start, end int64) float64 { return math.Max(float64(end-start)*scale, 0.5) }
+	ranges := make([][2]float64, len(tracks))
+	for i, t := range tracks {
+		ranges[i][0], ranges[i][1] = t.Range()
+	}
+
+	for i, chr := range chrs {
+		y := float64(top + i*rowHeight)
+		fmt.Fprintf(bw, `<g class="chr" id="%s">`+"\n", escape(chr))
+		fmt.Fprintf(bw, `<text x="%d" y="%.2f">%s</text>`+"\n", margin, y+chrHeight-3, escape(chr))
+
+		// Bands, centromere is drawn as triangles pointing to each other.
+		for _, rule := range cm.Rules {
+			if cytomap.NormalizeChr(rule.Chr) != chr {
+				continue
+			}
+			x0, x1 := x(rule.Start), x(rule.Start)+width(rule.Start, rule.End)
+			if rule.Color == "acen" {
+				tip, base := x1, x0
+				if strings.HasPrefix(rule.Section, "q") {
+					tip, base = x0, x1
+				}
+				fmt.Fprintf(bw, `<polygon points="%.2f,%.2f %.2f,%.2f %.2f,%.2f" fill="%s"><title>%s</title></polygon>`+"\n",
+					base, y, tip, y+chrHeight/2, base, y+chrHeight, StainColor(rule.Color), escape(rule.Section))
+				continue
+			}
+			fmt.Fprintf(bw, `<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="%s"><title>%s</title></rect>`+"\n",
+				x0, y, x1-x0, chrHeight, StainColor(rule.Color), escape(rule.Section))
+		}
+		fmt.Fprintf(bw, `<rect x="%.2f" y="%.2f" width="%.2f" height="%d" rx="4" fill="none" stroke="#000000" stroke-width="0.5"/>`+"\n",
+			left, y, float64(size[chr])*scale, chrHeight)
+
+		// Heat tracks.
+		for j, t := range tracks {
+			ty := y + float64(chrHeight+2+j*(trackHeight+2))
+			fmt.Fprintf(bw, `<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="#f0f0f0"/>`+"\n",
+				left, ty, float64(size[chr])*scale, trackHeight)
+			min, max := ranges[j][0], ranges[j][1]
+			for _, v := range t.Values {
+				if cytomap.NormalizeChr(v.Chr) != chr || math.IsNaN(v.V) {
+					continue
+				}
+				norm := 1.0
+				if max > min {
+					norm = (v.V - min) / (max - min)
+				}
+				fmt.Fprintf(bw, `<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="%s"><title>%s %g</title></rect>`+"\n",
+					x(v.Start), ty, width(v.Start, v.End), trackHeight, HeatColor(norm), escape(t.Name), v.V)
+			}
+		}
+		fmt.Fprintln(bw, "</g>")
+	}
+
+	// Legends of tracks.
+	for j, t := range tracks {
+		y := float64(top + len(chrs)*rowHeight + j*(trackHeight+rowGap))
+		fmt.Fprintf(bw, `<text x="%d" y="%.2f">%s</text>`+"\n", margin, y+trackHeight, escape(t.Name))
+		for k := 0; k < 10; k++ {
+			fmt.Fprintf(bw, `<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="%s"/>`+"\n",
+				left+float64(k*legendWidth)/10, y, float64(legendWidth)/10, trackHeight, HeatColor(float64(k)/9))
+		}
+		fmt.Fprintf(bw, `<text x="%.2f" y="%.2f">%g - %g</text>`+"\n",
+			left+legendWidth+5, y+trackHeight, ranges[j][0], ranges[j][1])
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
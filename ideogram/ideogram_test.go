@@ -0,0 +1,122 @@
+package ideogram
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/assoc"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func testMap() *cytomap.CytoMap {
+	cm := &cytomap.CytoMap{Hg: 19, Rules: []*cytomap.CytoRule{
+		{Chr: "chr10", Start: 0, End: 100, Section: "p15", Color: "gneg"},
+		{Chr: "chr2", Start: 0, End: 100, Section: "p25", Color: "gpos50"},
+		{Chr: "chr2", Start: 100, End: 150, Section: "p11", Color: "acen"},
+		{Chr: "chr2", Start: 150, End: 200, Section: "q11", Color: "acen"},
+		{Chr: "chr2", Start: 200, End: 400, Section: "q37", Color: "gvar"},
+	}}
+	cm.BuildIndex()
+	return cm
+}
+
+func TestColor(t *testing.T) {
+	Convey("Colors of stains and heat values", t, func() {
+		So(StainColor("gneg"), ShouldEqual, "#ffffff")
+		So(StainColor("gpos50"), ShouldEqual, "#808080")
+		So(StainColor("gpos100"), ShouldEqual, "#000000")
+		So(StainColor("acen"), ShouldEqual, "#d92f27")
+		So(StainColor("other"), ShouldEqual, "#ffffff")
+		So(HeatColor(0), ShouldEqual, "#ffffcc")
+		So(HeatColor(1), ShouldEqual, "#bd0026")
+		So(HeatColor(2), ShouldEqual, "#bd0026")
+	})
+}
+
+func TestTracks(t *testing.T) {
+	Convey("Build heat tracks of bands", t, func() {
+		cm := testMap()
+		tiles := []*tileset.Tile{
+			{Chr: "chr10", Start: 0, End: 50},
+			{Chr: "chr10", Start: 50, End: 100},
+			{Chr: "chr2", Start: 0, End: 50},
+			{Chr: "chr2", Start: 200, End: 300},
+		}
+		seq := bits.New(4)
+		seq.Set(0, bits.DT_SIMPLE, 0, 0).Set(1, bits.DT_SIMPLE, 0, 0).Set(3, bits.DT_SIMPLE, 0, 0)
+
+		tr, err := DiffCounts(cm, seq, tiles, bits.DT_SIMPLE)
+		So(err, ShouldBeNil)
+		So(tr.Name, ShouldEqual, "simple")
+		So(tr.Values[0].V, ShouldEqual, 2)
+		So(tr.Values[1].V, ShouldEqual, 0)
+		So(tr.Values[4].V, ShouldEqual, 1)
+		_, err = DiffCounts(cm, seq, tiles[:1], bits.DT_SIMPLE)
+		So(err, ShouldNotBeNil)
+
+		cm.AddTiles(tiles)
+		tr = TileDensity(cm)
+		So(tr.Values[0].V, ShouldEqual, 2e4)
+		min, max := tr.Range()
+		So(min, ShouldEqual, 0)
+		So(max, ShouldEqual, 2e4)
+
+		tr = PValues(cm, []*assoc.Result{
+			{Chr: "chr10", Start: 0, End: 50, PValue: 0.01},
+			{Chr: "chr10", Start: 50, End: 100, PValue: 0.001},
+			{Chr: "chr2", Start: 0, End: 50, Skipped: true},
+		})
+		So(tr.Values[0].V, ShouldAlmostEqual, 3)
+		So(math.IsNaN(tr.Values[1].V), ShouldBeTrue)
+		min, max = tr.Range()
+		So(min, ShouldAlmostEqual, 3)
+		So(max, ShouldAlmostEqual, 3)
+	})
+}
+
+func TestRender(t *testing.T) {
+	Convey("Render ideograms in SVG format", t, func() {
+		cm := testMap()
+		tr := &Track{Name: "count<1>", Values: []Value{
+			{"chr2", 0, 100, 1}, {"chr2", 200, 400, 3}, {"chr10", 0, 100, math.NaN()},
+		}}
+
+		var buf bytes.Buffer
+		So(Render(&buf, cm, []*Track{tr}, Options{Width: 400, Title: "Test"}), ShouldBeNil)
+		svg := buf.String()
+
+		// Output is well-formed XML.
+		dec := xml.NewDecoder(strings.NewReader(svg))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			So(err, ShouldBeNil)
+		}
+		So(strings.Index(svg, `id="chr2"`), ShouldBeLessThan, strings.Index(svg, `id="chr10"`))
+		So(strings.Count(svg, "<polygon"), ShouldEqual, 2)
+		So(svg, ShouldContainSubstring, `fill="#808080"`)
+		So(svg, ShouldContainSubstring, `fill="#ffffcc"><title>count&lt;1&gt; 1</title>`)
+		So(svg, ShouldContainSubstring, `fill="#bd0026"><title>count&lt;1&gt; 3</title>`)
+
+		buf.Reset()
+		So(Render(&buf, cm, nil, Options{Chrs: []string{"chr10"}}), ShouldBeNil)
+		So(buf.String(), ShouldNotContainSubstring, `id="chr2"`)
+
+		buf.Reset()
+		So(Render(&buf, cm, nil, Options{Chrs: []string{"10", "2"}}), ShouldBeNil)
+		So(strings.Index(buf.String(), `id="chr10"`), ShouldBeLessThan, strings.Index(buf.String(), `id="chr2"`))
+
+		So(Render(&buf, cm, nil, Options{Chrs: []string{"chr3"}}), ShouldNotBeNil)
+		So(Render(&buf, &cytomap.CytoMap{}, nil, DefaultOptions), ShouldEqual, ErrNoChromosome)
+	})
+}
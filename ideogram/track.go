@@ -0,0 +1,113 @@
+package ideogram
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/genomelightning/lightning/assoc"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Value represents value of a region in track.
+type Value struct {
+	Chr        string
+	Start, End int64
+	V          float64
+}
+
+// Track represents a heat track of values of regions.
+type Track struct {
+	Name   string
+	Values []Value
+}
+
+// Range returns minimum and maximum values of track, NaN values are ignored.
+// It returns 0, 0 when track has no value.
+func (t *Track) Range() (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, v := range t.Values {
+		if math.IsNaN(v.V) {
+			continue
+		}
+		min, max = math.Min(min, v.V), math.Max(max, v.V)
+	}
+	if math.IsInf(min, 1) {
+		return 0, 0
+	}
+	return min, max
+}
+
+// bandTrack returns track that has a zero value for every band.
+func bandTrack(name string, cm *cytomap.CytoMap) *Track {
+	t := &Track{Name: name, Values: make([]Value, len(cm.Rules))}
+	for i, rule := range cm.Rules {
+		t.Values[i] = Value{rule.Chr, rule.Start, rule.End, 0}
+	}
+	return t
+}
+
+// indexes returns indexes of rules in the map.
+func indexes(cm *cytomap.CytoMap) map[*cytomap.CytoRule]int {
+	idx := make(map[*cytomap.CytoRule]int, len(cm.Rules))
+	for i, rule := range cm.Rules {
+		idx[rule] = i
+	}
+	return idx
+}
+
+// DiffCounts returns track of numbers of tiles of DiffType dt in every band,
+// tiles are in the same order of bit sequence.
+func DiffCounts(cm *cytomap.CytoMap, b bits.Bitmap, tiles []*tileset.Tile, dt bits.DiffType) (*Track, error) {
+	if int(b.Len()) != len(tiles) {
+		return nil, fmt.Errorf("ideogram: %d tiles for bit sequence of length %d", len(tiles), b.Len())
+	}
+
+	t := bandTrack(dt.String(), cm)
+	idx := indexes(cm)
+	for i, tile := range tiles {
+		if b.Get(uint64(i)) != dt {
+			continue
+		}
+		if rule := cm.Find(tile.Chr, tile.Start, tile.End); rule != nil {
+			t.Values[idx[rule]].V++
+		}
+	}
+	return t, nil
+}
+
+// TileDensity returns track of numbers of tiles per megabase in every band.
+func TileDensity(cm *cytomap.CytoMap) *Track {
+	t := bandTrack("tiles/Mb", cm)
+	for i, rule := range cm.Rules {
+		if size := rule.End - rule.Start; size > 0 {
+			t.Values[i].V = float64(len(rule.Tiles)) * 1e6 / float64(size)
+		}
+	}
+	return t
+}
+
+// PValues returns track of -log10 of the minimum p-value of results in every
+// band, results need coordinates and bands without tested tiles are NaN.
+func PValues(cm *cytomap.CytoMap, results []*assoc.Result) *Track {
+	t := bandTrack("-log10(p)", cm)
+	for i := range t.Values {
+		t.Values[i].V = math.NaN()
+	}
+	idx := indexes(cm)
+	for _, res := range results {
+		if res.Skipped || len(res.Chr) == 0 {
+			continue
+		}
+		rule := cm.Find(res.Chr, res.Start, res.End)
+		if rule == nil {
+			continue
+		}
+		v := -math.Log10(math.Max(res.PValue, math.SmallestNonzeroFloat64))
+		if i := idx[rule]; math.IsNaN(t.Values[i].V) || v > t.Values[i].V {
+			t.Values[i].V = v
+		}
+	}
+	return t
+}
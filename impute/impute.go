@@ -0,0 +1,256 @@
+// Package impute imputes unknown tiles of tiled genome from a reference panel
+// of haplotypes with a Li-Stephens hidden Markov model over tile variants.
+package impute
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/genomelightning/lightning/genome"
+)
+
+// ErrNoPanel is returned when reference panel does not have any haplotype.
+var ErrNoPanel = errors.New("impute: no haplotype in panel")
+
+// Options represents options of imputation.
+type Options struct {
+	Switch        float64 // Probability of switching haplotype between neighboring tiles.
+	Error         float64 // Probability of variant that differs from copied haplotype.
+	Flank         int     // Number of tiles on each side of unknown tiles that are used.
+	MinConfidence float64 // Minimum confidence of tiles that are imputed.
+}
+
+// DefaultOptions is the default options of imputation.
+var DefaultOptions = Options{Switch: 0.01, Error: 0.01, Flank: 50}
+
+// Panel represents reference panel of haplotypes that are numbered by
+// variants of tiles, number 0 is unknown.
+type Panel struct {
+	Variants *genome.Variants
+	haps     [][]int
+}
+
+// NewPanel returns a new panel of haplotypes, variants are numbered against
+// reference. Invalid blocks and blocks with mixed tags of haplotypes are unknown,
+// and so are blocks with masked bases that do not match any variant on called bases.
+func NewPanel(ref *genome.Sequence, haps []*genome.Sequence) (*Panel, error) {
+	if len(haps) == 0 {
+		return nil, ErrNoPanel
+	}
+	p := &Panel{Variants: genome.NewVariants(ref), haps: make([][]int, len(haps))}
+	for h, s := range haps {
+		if s.Length() != ref.Length() {
+			return nil, genome.ErrLengthMismatch
+		}
+		p.haps[h] = make([]int, s.Length())
+		for i, b := range s.Blocks {
+			if b.Valid && b.NumMixedTag == 0 && !masked(b) {
+				p.haps[h][i] = p.Variants.Number(i, b.Data)
+			}
+		}
+	}
+	// Masked blocks are matched after all variants are numbered.
+	for h, s := range haps {
+		for i, b := range s.Blocks {
+			if b.Valid && b.NumMixedTag == 0 && masked(b) {
+				p.haps[h][i] = p.Variants.Match(i, b)
+			}
+		}
+	}
+	return p, nil
+}
+
+// masked returns true when block has bases that are not called.
+func masked(b *genome.Block) bool {
+	return len(b.NoCalls) > 0 || bytes.IndexByte(b.Data, 'N') >= 0
+}
+
+// Len returns the number of haplotypes in panel.
+func (p *Panel) Len() int {
+	return len(p.haps)
+}
+
+// Impute fills blocks of sequence that are invalid or do not meet thresholds
+// with the most likely variants of panel, and returns filled sequence with
+// confidence of every tile. Confidence is 1 for valid blocks, posterior
+// probability of variant for imputed blocks and 0 for blocks that are left
+// unknown. Imputed blocks have Phred-scaled quality of confidence, blocks that
+// are left unknown are filtered by th, and other blocks are shared with s.
+// Valid blocks are matched to variants of panel on called bases.
+func (p *Panel) Impute(s *genome.Sequence, th genome.Thresholds, opt Options) (*genome.Sequence, []float64, error) {
+	n := s.Length()
+	if len(p.haps) > 0 && len(p.haps[0]) != n {
+		return nil, nil, genome.ErrLengthMismatch
+	}
+
+	s = th.Filter(s)
+	fs := &genome.Sequence{Blocks: make([]*genome.Block, n)}
+	copy(fs.Blocks, s.Blocks)
+	confs := make([]float64, n)
+	// Variants that are not in panel are 0 as unknown, they are equally
+	// unlikely for all haplotypes.
+	obs := make([]int, n)
+	for i, b := range s.Blocks {
+		if b.Valid {
+			confs[i] = 1
+			if b.NumMixedTag == 0 {
+				obs[i] = p.Variants.Match(i, b)
+			}
+		}
+	}
+
+	// Windows of runs of unknown tiles with flanks, windows that overlap are merged.
+	for start := 0; start < n; {
+		if s.Blocks[start].Valid {
+			start++
+			continue
+		}
+		end := start + 1
+		for end < n && (!s.Blocks[end].Valid || p.nextUnknown(s, end, opt.Flank)) {
+			end++
+		}
+		lo, hi := start-opt.Flank, end+opt.Flank
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > n {
+			hi = n
+		}
+		p.imputeWindow(fs, confs, obs, lo, hi, opt)
+		start = end
+	}
+	return fs, confs, nil
+}
+
+// nextUnknown returns true when there is an invalid block in (i, i+2*flank],
+// i.e. flanks of runs of unknown tiles overlap.
+func (p *Panel) nextUnknown(s *genome.Sequence, i, flank int) bool {
+	for j := i + 1; j <= i+2*flank && j < s.Length(); j++ {
+		if !s.Blocks[j].Valid {
+			return true
+		}
+	}
+	return false
+}
+
+// imputeWindow runs forward-backward algorithm in tiles [lo, hi) and imputes
+// invalid blocks by posterior probabilities of copied haplotypes.
+func (p *Panel) imputeWindow(fs *genome.Sequence, confs []float64, obs []int, lo, hi int, opt Options) {
+	nh := len(p.haps)
+	emit := func(t, h int) float64 {
+		v := p.haps[h][t]
+		switch {
+		case obs[t] == 0 || v == 0:
+			return 1
+		case obs[t] == v:
+			return 1 - opt.Error
+		}
+		return opt.Error
+	}
+	normalize := func(ps []float64) {
+		sum := 0.0
+		for _, v := range ps {
+			sum += v
+		}
+		if sum == 0 {
+			for h := range ps {
+				ps[h] = 1 / float64(len(ps))
+			}
+			return
+		}
+		for h := range ps {
+			ps[h] /= sum
+		}
+	}
+
+	// Forward probabilities are normalized at every tile, so the probability of
+	// switching to any haplotype is Switch/nh.
+	l := hi - lo
+	fwd := make([][]float64, l)
+	for t := 0; t < l; t++ {
+		fwd[t] = make([]float64, nh)
+		for h := 0; h < nh; h++ {
+			prior := 1 / float64(nh)
+			if t > 0 {
+				prior = (1-opt.Switch)*fwd[t-1][h] + opt.Switch/float64(nh)
+			}
+			fwd[t][h] = prior * emit(lo+t, h)
+		}
+		normalize(fwd[t])
+	}
+
+	bwd := make([]float64, nh)
+	next := make([]float64, nh)
+	for h := range bwd {
+		bwd[h] = 1
+	}
+	post := make([]float64, nh)
+	scores := make(map[int]float64)
+	for t := l - 1; t >= 0; t-- {
+		if t < l-1 {
+			// Backward probabilities of tile t from tile t+1.
+			sum := 0.0
+			for h := 0; h < nh; h++ {
+				next[h] = emit(lo+t+1, h) * bwd[h]
+				sum += next[h]
+			}
+			for h := 0; h < nh; h++ {
+				bwd[h] = (1-opt.Switch)*next[h] + opt.Switch/float64(nh)*sum
+			}
+			normalize(bwd)
+		}
+
+		i := lo + t
+		if fs.Blocks[i].Valid {
+			continue
+		}
+		for h := 0; h < nh; h++ {
+			post[h] = fwd[t][h] * bwd[h]
+		}
+		normalize(post)
+
+		// Haplotypes that are unknown at the tile do not vote.
+		for k := range scores {
+			delete(scores, k)
+		}
+		best, total := 0, 0.0
+		for h := 0; h < nh; h++ {
+			if v := p.haps[h][i]; v > 0 {
+				scores[v] += post[h]
+				total += post[h]
+				if best == 0 || scores[v] > scores[best] || scores[v] == scores[best] && v < best {
+					best = v
+				}
+			}
+		}
+		if best == 0 {
+			continue
+		}
+		conf := scores[best] / total
+		if conf < opt.MinConfidence {
+			continue
+		}
+		confs[i] = conf
+		fs.Blocks[i] = &genome.Block{
+			Valid:   true,
+			Data:    p.Variants.Data(i, best),
+			Quality: phred(conf),
+		}
+	}
+}
+
+// maxQuality is the upper bound of Phred-scaled quality of imputed blocks.
+const maxQuality = 60
+
+// phred returns Phred-scaled quality of probability of being correct.
+func phred(p float64) int {
+	if p >= 1 {
+		return maxQuality
+	}
+	q := int(-10 * math.Log10(1-p))
+	if q > maxQuality {
+		return maxQuality
+	}
+	return q
+}
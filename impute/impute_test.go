@@ -0,0 +1,125 @@
+package impute
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/genome"
+)
+
+// newSeq returns sequence of tiles with variants in letters, '.' is invalid.
+func newSeq(variants string) *genome.Sequence {
+	s := &genome.Sequence{}
+	for _, v := range []byte(variants) {
+		b := &genome.Block{Valid: v != '.', Data: []byte{v}}
+		if !b.Valid {
+			b.Data = nil
+		}
+		s.Blocks = append(s.Blocks, b)
+	}
+	return s
+}
+
+func TestImpute(t *testing.T) {
+	Convey("Impute unknown tiles from reference panel", t, func() {
+		ref := newSeq("AAAAAAAAAAAA")
+		p, err := NewPanel(ref, []*genome.Sequence{
+			newSeq("ACACACACACAC"),
+			newSeq("AACCAACCAACC"),
+			newSeq("CCCCAAAACCCC"),
+			newSeq("ACCAACCAA.CA"),
+		})
+		So(err, ShouldBeNil)
+		So(p.Len(), ShouldEqual, 4)
+		So(p.Variants.Count(1), ShouldEqual, 2)
+
+		// Copies the first haplotype and then the second one.
+		s := newSeq("AC.CA.CCA..C")
+		fs, confs, err := p.Impute(s, genome.Thresholds{}, DefaultOptions)
+		So(err, ShouldBeNil)
+		So(fs.Length(), ShouldEqual, s.Length())
+		var data []byte
+		for i, b := range fs.Blocks {
+			So(b.Valid, ShouldBeTrue)
+			data = append(data, b.Data...)
+			if s.Blocks[i].Valid {
+				So(b, ShouldEqual, s.Blocks[i])
+				So(confs[i], ShouldEqual, 1)
+			} else {
+				So(confs[i], ShouldBeGreaterThan, 0.5)
+				So(b.Quality, ShouldBeGreaterThan, 0)
+			}
+		}
+		So(string(data), ShouldEqual, "ACACAACCAACC")
+		// Original sequence is not changed.
+		So(s.Blocks[2].Valid, ShouldBeFalse)
+
+		// Tiles are left unknown when confidence is too low.
+		opt := DefaultOptions
+		opt.MinConfidence = 1
+		fs, confs, err = p.Impute(s, genome.Thresholds{}, opt)
+		So(err, ShouldBeNil)
+		So(fs.Blocks[2].Valid, ShouldBeFalse)
+		So(confs[2], ShouldEqual, 0)
+
+		// Without flanks, tiles are imputed by frequency of panel, haplotypes that
+		// are unknown do not vote.
+		opt = DefaultOptions
+		opt.Flank = 0
+		fs, confs, err = p.Impute(newSeq("..........AA"), genome.Thresholds{}, opt)
+		So(err, ShouldBeNil)
+		So(string(fs.Blocks[0].Data), ShouldEqual, "A")
+		So(confs[0], ShouldAlmostEqual, 0.75)
+		So(string(fs.Blocks[1].Data), ShouldEqual, "C")
+		So(confs[1], ShouldAlmostEqual, 0.75)
+		So(string(fs.Blocks[9].Data), ShouldEqual, "C")
+		So(confs[9], ShouldAlmostEqual, 2.0/3)
+
+		_, _, err = p.Impute(newSeq("AA"), genome.Thresholds{}, DefaultOptions)
+		So(err, ShouldEqual, genome.ErrLengthMismatch)
+		_, err = NewPanel(ref, nil)
+		So(err, ShouldEqual, ErrNoPanel)
+		_, err = NewPanel(ref, []*genome.Sequence{newSeq("AA")})
+		So(err, ShouldEqual, genome.ErrLengthMismatch)
+
+		// Masked blocks are matched on called bases, and blocks that do not
+		// meet thresholds are imputed.
+		blocks := func(data ...string) *genome.Sequence {
+			s := &genome.Sequence{}
+			for _, d := range data {
+				s.Blocks = append(s.Blocks, &genome.Block{Valid: true, Data: []byte(d)})
+			}
+			return s
+		}
+		p, err = NewPanel(blocks("AA", "AA", "AA"), []*genome.Sequence{
+			blocks("NC", "CA", "AC"),
+			blocks("AC", "CA", "AC"),
+			blocks("CA", "AC", "CA"),
+			blocks("NN", "AC", "CA"),
+		})
+		So(err, ShouldBeNil)
+		So(p.haps[0][0], ShouldEqual, 2)
+		So(p.haps[3][0], ShouldEqual, 0)
+		So(p.Variants.Count(0), ShouldEqual, 3)
+
+		s = blocks("AC", "CA", "CA")
+		s.Blocks[1].NoCalls = []genome.Range{{Start: 1, End: 2}}
+		s.Blocks[2].Quality = 5
+		fs, confs, err = p.Impute(s, genome.Thresholds{MinQuality: 20}, DefaultOptions)
+		So(err, ShouldBeNil)
+		So(fs.Blocks[1], ShouldEqual, s.Blocks[1])
+		So(string(fs.Blocks[2].Data), ShouldEqual, "AC")
+		So(confs[2], ShouldBeGreaterThan, 0.5)
+		So(s.Blocks[2].Valid, ShouldBeTrue)
+	})
+}
+
+func TestPhred(t *testing.T) {
+	Convey("Phred-scaled quality of probability", t, func() {
+		So(phred(0.9), ShouldEqual, 10)
+		So(phred(0.5), ShouldEqual, 3)
+		So(phred(1), ShouldEqual, maxQuality)
+		So(phred(0.9999999999), ShouldEqual, maxQuality)
+	})
+}
@@ -0,0 +1,183 @@
+// Package interval indexes ranges on chromosomes for overlap queries.
+package interval
+
+import "sort"
+
+type item struct {
+	start, end int64
+	max        int64 // Maximum end of subtree.
+	id         int
+}
+
+// tree is an implicit augmented interval tree of items sorted by start,
+// a node at level k has index with k trailing ones.
+type tree struct {
+	items    []item
+	maxLevel int
+}
+
+func (t *tree) build() {
+	sort.SliceStable(t.items, func(i, j int) bool {
+		return t.items[i].start < t.items[j].start
+	})
+
+	a, n := t.items, len(t.items)
+	if n == 0 {
+		t.maxLevel = -1
+		return
+	}
+	var (
+		lastI int
+		last  int64
+	)
+	for i := 0; i < n; i += 2 {
+		lastI, last = i, a[i].end
+		a[i].max = a[i].end
+	}
+	k := 1
+	for ; 1<<uint(k) <= n; k++ {
+		x := 1 << uint(k-1)
+		step := x << 2
+		for i := x<<1 - 1; i < n; i += step {
+			max := a[i].end
+			if el := a[i-x].max; el > max {
+				max = el
+			}
+			er := last
+			if i+x < n {
+				er = a[i+x].max
+			}
+			if er > max {
+				max = er
+			}
+			a[i].max = max
+		}
+		if lastI>>uint(k)&1 == 1 {
+			lastI -= x
+		} else {
+			lastI += x
+		}
+		if lastI < n && a[lastI].max > last {
+			last = a[lastI].max
+		}
+	}
+	t.maxLevel = k - 1
+}
+
+type frame struct {
+	x, k int
+	left bool // Whether left subtree has been visited.
+}
+
+// overlap calls fn for items that overlap [start, end) in order of start.
+func (t *tree) overlap(start, end int64, fn func(it *item)) {
+	a, n := t.items, len(t.items)
+	if t.maxLevel < 0 {
+		return
+	}
+	stack := []frame{{1<<uint(t.maxLevel) - 1, t.maxLevel, false}}
+	for len(stack) > 0 {
+		z := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch {
+		case z.k <= 3:
+			// Scan small subtree linearly.
+			i0 := z.x >> uint(z.k) << uint(z.k)
+			i1 := i0 + 1<<uint(z.k+1) - 1
+			if i1 > n {
+				i1 = n
+			}
+			for i := i0; i < i1 && a[i].start < end; i++ {
+				if start < a[i].end {
+					fn(&a[i])
+				}
+			}
+		case !z.left:
+			stack = append(stack, frame{z.x, z.k, true})
+			if y := z.x - 1<<uint(z.k-1); y >= n || a[y].max > start {
+				stack = append(stack, frame{y, z.k - 1, false})
+			}
+		case z.x < n && a[z.x].start < end:
+			if start < a[z.x].end {
+				fn(&a[z.x])
+			}
+			stack = append(stack, frame{z.x + 1<<uint(z.k-1), z.k - 1, false})
+		}
+	}
+}
+
+// Index is an index of half-open ranges [start, end) on chromosomes,
+// ranges are identified by the order they are added, start from 0.
+// Index must be built before queries and is safe for concurrent queries.
+type Index struct {
+	chrs  map[string]*tree
+	n     int
+	built bool
+}
+
+// NewIndex returns a new empty index.
+func NewIndex() *Index {
+	return &Index{chrs: make(map[string]*tree)}
+}
+
+// Add adds a range to the index and returns its ID.
+func (idx *Index) Add(chr string, start, end int64) int {
+	t, ok := idx.chrs[chr]
+	if !ok {
+		t = &tree{}
+		idx.chrs[chr] = t
+	}
+	t.items = append(t.items, item{start: start, end: end, id: idx.n})
+	idx.n++
+	idx.built = false
+	return idx.n - 1
+}
+
+// Len returns the number of ranges in the index.
+func (idx *Index) Len() int {
+	return idx.n
+}
+
+// Build builds the index for queries, it has to be called again
+// after more ranges are added.
+func (idx *Index) Build() {
+	for _, t := range idx.chrs {
+		t.build()
+	}
+	idx.built = true
+}
+
+// Built returns true if the index is ready for queries.
+func (idx *Index) Built() bool {
+	return idx.built
+}
+
+// Overlap returns IDs of ranges that overlap [start, end) in order of start.
+func (idx *Index) Overlap(chr string, start, end int64) []int {
+	var ids []int
+	if t, ok := idx.chrs[chr]; ok {
+		t.overlap(start, end, func(it *item) {
+			ids = append(ids, it.id)
+		})
+	}
+	return ids
+}
+
+// Contain returns IDs of ranges that contain [start, end) in order of start.
+func (idx *Index) Contain(chr string, start, end int64) []int {
+	var ids []int
+	t, ok := idx.chrs[chr]
+	if !ok {
+		return nil
+	}
+	qend := end
+	if qend <= start {
+		qend = start + 1
+	}
+	t.overlap(start, qend, func(it *item) {
+		if it.start <= start && end <= it.end {
+			ids = append(ids, it.id)
+		}
+	})
+	return ids
+}
@@ -0,0 +1,70 @@
+package interval
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIndex(t *testing.T) {
+	Convey("Query ranges that overlap or contain given range", t, func() {
+		idx := NewIndex()
+		So(idx.Add("chr1", 10, 20), ShouldEqual, 0)
+		idx.Add("chr1", 0, 100)
+		idx.Add("chr1", 15, 30)
+		idx.Add("chr2", 10, 20)
+		idx.Add("chr1", 40, 50)
+		So(idx.Len(), ShouldEqual, 5)
+		So(idx.Built(), ShouldBeFalse)
+		idx.Build()
+		So(idx.Built(), ShouldBeTrue)
+
+		So(idx.Overlap("chr1", 18, 41), ShouldResemble, []int{1, 0, 2, 4})
+		So(idx.Overlap("chr1", 20, 40), ShouldResemble, []int{1, 2})
+		So(idx.Overlap("chr1", 100, 200), ShouldBeNil)
+		So(idx.Overlap("chr3", 0, 200), ShouldBeNil)
+		So(idx.Contain("chr1", 16, 20), ShouldResemble, []int{1, 0, 2})
+		So(idx.Contain("chr1", 45, 45), ShouldResemble, []int{1, 4})
+		So(idx.Contain("chr2", 5, 15), ShouldBeNil)
+
+		So(NewIndex().Overlap("chr1", 0, 10), ShouldBeNil)
+	})
+
+	Convey("Results match linear scan", t, func() {
+		r := rand.New(rand.NewSource(1))
+		for _, n := range []int{1, 2, 3, 7, 16, 100, 1000} {
+			type rng struct{ start, end int64 }
+			rngs := make([]rng, n)
+			idx := NewIndex()
+			for i := range rngs {
+				start := r.Int63n(10000)
+				rngs[i] = rng{start, start + r.Int63n(500) + 1}
+				if i%50 == 0 {
+					rngs[i].end += 5000
+				}
+				idx.Add("chr1", rngs[i].start, rngs[i].end)
+			}
+			idx.Build()
+
+			for q := 0; q < 100; q++ {
+				start := r.Int63n(11000)
+				end := start + r.Int63n(300) + 1
+				expect := map[int]bool{}
+				for i, rg := range rngs {
+					if rg.start < end && start < rg.end {
+						expect[i] = true
+					}
+				}
+				ids := idx.Overlap("chr1", start, end)
+				So(len(ids), ShouldEqual, len(expect))
+				for i, id := range ids {
+					So(expect[id], ShouldBeTrue)
+					if i > 0 {
+						So(rngs[ids[i-1]].start, ShouldBeLessThanOrEqualTo, rngs[id].start)
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,135 @@
+// Package kinship computes pairwise distances and relatedness of genomes.
+package kinship
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/genomelightning/lightning/bits"
+)
+
+// DefaultBlockSize is the number of samples in one side of a block.
+const DefaultBlockSize = 64
+
+// Matrix represents symmetric distance matrix of samples.
+type Matrix struct {
+	Size     int
+	Diffs    []uint64 // Number of different tiles, row-major.
+	Compared []uint64 // Number of tiles that both samples are known.
+}
+
+// NewMatrix initializes a new matrix for given number of samples.
+func NewMatrix(size int) *Matrix {
+	return &Matrix{
+		Size:     size,
+		Diffs:    make([]uint64, size*size),
+		Compared: make([]uint64, size*size),
+	}
+}
+
+func (m *Matrix) set(i, j int, diff, compared uint64) {
+	m.Diffs[i*m.Size+j], m.Diffs[j*m.Size+i] = diff, diff
+	m.Compared[i*m.Size+j], m.Compared[j*m.Size+i] = compared, compared
+}
+
+// Diff returns number of different tiles between sample i and j.
+func (m *Matrix) Diff(i, j int) uint64 {
+	return m.Diffs[i*m.Size+j]
+}
+
+// Distance returns fraction of compared tiles that differ between sample i and j.
+func (m *Matrix) Distance(i, j int) float64 {
+	n := m.Compared[i*m.Size+j]
+	if n == 0 {
+		return 0
+	}
+	return float64(m.Diffs[i*m.Size+j]) / float64(n)
+}
+
+// Kinship returns fraction of compared tiles that are same between sample i and j.
+func (m *Matrix) Kinship(i, j int) float64 {
+	return 1 - m.Distance(i, j)
+}
+
+// block represents a block of sample pairs.
+type block struct {
+	row, col int
+}
+
+// Compute computes distance matrix of all sample pairs.
+// Pairs are grouped into blocks of blockSize×blockSize and computed
+// in parallel by given number of workers, zero means use defaults.
+func Compute(seqs []*bits.Sequence, blockSize, workers int) (*Matrix, error) {
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i].Len() != seqs[0].Len() {
+			return nil, bits.ErrLengthMismatch
+		}
+	}
+
+	m := NewMatrix(len(seqs))
+	blocks := make(chan block)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range blocks {
+				computeBlock(m, seqs, b, blockSize)
+			}
+		}()
+	}
+
+	for row := 0; row < len(seqs); row += blockSize {
+		for col := row; col < len(seqs); col += blockSize {
+			blocks <- block{row, col}
+		}
+	}
+	close(blocks)
+	wg.Wait()
+	return m, nil
+}
+
+func computeBlock(m *Matrix, seqs []*bits.Sequence, b block, blockSize int) {
+	for i := b.row; i < b.row+blockSize && i < len(seqs); i++ {
+		j := b.col
+		if b.row == b.col {
+			j = i
+		}
+		for ; j < b.col+blockSize && j < len(seqs); j++ {
+			// Length has been checked, error is impossible here.
+			diff, compared, _ := bits.Distance(seqs[i], seqs[j])
+			m.set(i, j, diff, compared)
+		}
+	}
+}
+
+// WriteTSV writes normalized distance matrix in tab-separated format,
+// the first row and column are sample names.
+func (m *Matrix) WriteTSV(w io.Writer, names []string) error {
+	if len(names) != m.Size {
+		return fmt.Errorf("kinship: %d names for %d samples", len(names), m.Size)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		fmt.Fprintf(bw, "\t%s", name)
+	}
+	bw.WriteString("\n")
+	for i := 0; i < m.Size; i++ {
+		bw.WriteString(names[i])
+		for j := 0; j < m.Size; j++ {
+			fmt.Fprintf(bw, "\t%.6f", m.Distance(i, j))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
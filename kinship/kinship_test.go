@@ -0,0 +1,46 @@
+package kinship
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/bits"
+)
+
+func TestCompute(t *testing.T) {
+	Convey("Compute distance matrix of samples", t, func() {
+		seqs := make([]*bits.Sequence, 5)
+		for i := range seqs {
+			seqs[i] = bits.New(100)
+			for j := 0; j < i; j++ {
+				seqs[i].Set(uint64(j*10), bits.DT_SIMPLE, 1, 2)
+			}
+		}
+
+		m, err := Compute(seqs, 2, 3)
+		So(err, ShouldBeNil)
+		for i := range seqs {
+			for j := range seqs {
+				d := i - j
+				if d < 0 {
+					d = -d
+				}
+				So(m.Diff(i, j), ShouldEqual, d)
+			}
+		}
+		So(m.Distance(0, 4), ShouldAlmostEqual, 0.04)
+		So(m.Kinship(0, 4), ShouldAlmostEqual, 0.96)
+
+		var buf bytes.Buffer
+		So(m.WriteTSV(&buf, []string{"a", "b"}), ShouldNotBeNil)
+		So(m.WriteTSV(&buf, []string{"a", "b", "c", "d", "e"}), ShouldBeNil)
+		So(buf.String(), ShouldStartWith, "\ta\tb\tc\td\te\na\t0.000000\t0.010000")
+	})
+
+	Convey("Compute distance matrix of samples with different length", t, func() {
+		_, err := Compute([]*bits.Sequence{bits.New(10), bits.New(20)}, 0, 0)
+		So(err, ShouldEqual, bits.ErrLengthMismatch)
+	})
+}
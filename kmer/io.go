@@ -0,0 +1,156 @@
+package kmer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// magic is the file header of serialized index.
+var magic = [4]byte{'L', 'K', 'M', 'I'}
+
+// maxPrealloc is the maximum number of records that are allocated before
+// they are read.
+const maxPrealloc = 1 << 20
+
+// maxNameSize is the maximum size of contig names.
+const maxNameSize = 1 << 16
+
+// ErrInvalidFormat is returned when data is not a serialized index.
+var ErrInvalidFormat = errors.New("kmer: invalid format")
+
+// WriteTo writes index in binary format:
+//
+//	magic(4 bytes) k(uint32) contigs(uint32)
+//	[name size(uint32) name length(uint64)]... count(uint64)
+//	kmers(uint64...) positions(uint32...)
+//
+// All numbers are in little endian.
+func (idx *Index) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriterSize(w, 1<<20)
+	buf := make([]byte, 8)
+	n := int64(0)
+	write := func(b []byte) {
+		bw.Write(b)
+		n += int64(len(b))
+	}
+
+	write(magic[:])
+	binary.LittleEndian.PutUint32(buf, uint32(idx.K))
+	write(buf[:4])
+	binary.LittleEndian.PutUint32(buf, uint32(len(idx.Contigs)))
+	write(buf[:4])
+	for _, c := range idx.Contigs {
+		if len(c.Name) > maxNameSize {
+			return 0, errors.New("kmer: contig name is too long: " + c.Name[:32] + "...")
+		}
+		binary.LittleEndian.PutUint32(buf, uint32(len(c.Name)))
+		write(buf[:4])
+		write([]byte(c.Name))
+		binary.LittleEndian.PutUint64(buf, c.Length)
+		write(buf)
+	}
+	binary.LittleEndian.PutUint64(buf, uint64(len(idx.kmers)))
+	write(buf)
+	for _, km := range idx.kmers {
+		binary.LittleEndian.PutUint64(buf, km)
+		write(buf)
+	}
+	for _, p := range idx.pos {
+		binary.LittleEndian.PutUint32(buf, p)
+		write(buf[:4])
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Read reads index in binary format that is written by WriteTo.
+func Read(r io.Reader) (*Index, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	buf := make([]byte, 8)
+	read := func(b []byte) error {
+		_, err := io.ReadFull(br, b)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	if _, err := io.ReadFull(br, buf[:4]); err != nil {
+		return nil, err
+	}
+	if string(buf[:4]) != string(magic[:]) {
+		return nil, ErrInvalidFormat
+	}
+	if err := read(buf); err != nil {
+		return nil, err
+	}
+	idx := &Index{K: int(binary.LittleEndian.Uint32(buf))}
+	if idx.K < 1 || idx.K > MaxK {
+		return nil, ErrInvalidFormat
+	}
+
+	var total uint64
+	for i := binary.LittleEndian.Uint32(buf[4:]); i > 0; i-- {
+		if err := read(buf[:4]); err != nil {
+			return nil, err
+		}
+		size := binary.LittleEndian.Uint32(buf)
+		if size > maxNameSize {
+			return nil, ErrInvalidFormat
+		}
+		name := make([]byte, size)
+		if err := read(name); err != nil {
+			return nil, err
+		}
+		if err := read(buf); err != nil {
+			return nil, err
+		}
+		c := Contig{Name: string(name), Offset: total, Length: binary.LittleEndian.Uint64(buf)}
+		total += c.Length
+		idx.Contigs = append(idx.Contigs, c)
+	}
+	if total > math.MaxUint32 {
+		return nil, ErrInvalidFormat
+	}
+
+	if err := read(buf); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint64(buf)
+	if n > total {
+		return nil, ErrInvalidFormat
+	}
+	// Slices grow as records are read, so corrupt count does not allocate
+	// memory beyond data.
+	c := n
+	if c > maxPrealloc {
+		c = maxPrealloc
+	}
+	idx.kmers, idx.pos = make([]uint64, 0, c), make([]uint32, 0, c)
+	for i := uint64(0); i < n; i++ {
+		if err := read(buf); err != nil {
+			return nil, err
+		}
+		km := binary.LittleEndian.Uint64(buf)
+		if i > 0 && km < idx.kmers[i-1] {
+			return nil, ErrInvalidFormat
+		}
+		idx.kmers = append(idx.kmers, km)
+	}
+	for i := uint64(0); i < n; i++ {
+		if err := read(buf[:4]); err != nil {
+			return nil, err
+		}
+		p := binary.LittleEndian.Uint32(buf)
+		if uint64(p) >= total {
+			return nil, ErrInvalidFormat
+		}
+		idx.pos = append(idx.pos, p)
+	}
+	return idx, nil
+}
@@ -0,0 +1,55 @@
+package kmer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteTo(t *testing.T) {
+	Convey("Write and read index in binary format", t, func() {
+		idx, err := Build(map[string][]byte{
+			"chr1": []byte("ACGTACGGT"),
+			"chrX": []byte("GGTTNA"),
+		}, 3)
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		n, err := idx.WriteTo(&buf)
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, buf.Len())
+		data := buf.Bytes()
+
+		idx2, err := Read(bytes.NewReader(data))
+		So(err, ShouldBeNil)
+		So(idx2, ShouldResemble, idx)
+
+		_, err = Read(bytes.NewReader(data[:len(data)-1]))
+		So(err, ShouldEqual, io.ErrUnexpectedEOF)
+		_, err = Read(bytes.NewReader([]byte("LKMX\x03\x00\x00\x00")))
+		So(err, ShouldEqual, ErrInvalidFormat)
+
+		// Huge count in header without data.
+		huge := []byte("LKMI\x03\x00\x00\x00\x01\x00\x00\x00\x01\x00\x00\x00c")
+		huge = binary.LittleEndian.AppendUint64(huge, 1<<32-1)
+		huge = binary.LittleEndian.AppendUint64(huge, 1<<32-2)
+		_, err = Read(bytes.NewReader(huge))
+		So(err, ShouldEqual, io.ErrUnexpectedEOF)
+
+		// Huge contig name size.
+		_, err = Read(bytes.NewReader([]byte("LKMI\x03\x00\x00\x00\x01\x00\x00\x00\xff\xff\xff\xff")))
+		So(err, ShouldEqual, ErrInvalidFormat)
+		_, err = (&Index{K: 3, Contigs: []Contig{{Name: strings.Repeat("c", maxNameSize+1)}}}).WriteTo(&buf)
+		So(err, ShouldNotBeNil)
+
+		// Position out of genome.
+		bad := append([]byte(nil), data...)
+		bad[len(bad)-1] = 0xff
+		_, err = Read(bytes.NewReader(bad))
+		So(err, ShouldEqual, ErrInvalidFormat)
+	})
+}
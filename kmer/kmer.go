@@ -0,0 +1,269 @@
+// Package kmer indexes k-mers of genome in 2-bit packed form for exact lookup
+// and counting of tags.
+package kmer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/genomelightning/lightning/cytomap"
+)
+
+// MaxK is the maximum length of k-mers that can be packed in a word.
+const MaxK = 32
+
+var (
+	// ErrInvalidK is returned when length of k-mers is out of range.
+	ErrInvalidK = errors.New("kmer: length of k-mers must be in [1, 32]")
+	// ErrTooLarge is returned when genome has more bases than positions can hold.
+	ErrTooLarge = errors.New("kmer: genome is too large")
+)
+
+var baseCodes = func() (codes [256]int8) {
+	for i := range codes {
+		codes[i] = -1
+	}
+	for i, b := range []byte("ACGT") {
+		codes[b] = int8(i)
+		codes[b+'a'-'A'] = int8(i)
+	}
+	return codes
+}()
+
+var complements = func() (comps [256]byte) {
+	for i := range comps {
+		comps[i] = byte(i)
+	}
+	for _, p := range []string{"AT", "CG", "GC", "TA", "at", "cg", "gc", "ta"} {
+		comps[p[0]] = p[1]
+	}
+	return comps
+}()
+
+// BaseCode returns 2-bit code of base in either case that is used in packed
+// k-mers, it returns -1 for bases other than A, C, G and T.
+func BaseCode(b byte) int {
+	return int(baseCodes[b])
+}
+
+// AppendReverseComplement appends reverse complement of seq to dst and returns
+// the extended slice. Case of bases is kept, and bases other than A, C, G and T
+// are not changed.
+func AppendReverseComplement(dst, seq []byte) []byte {
+	for i := len(seq) - 1; i >= 0; i-- {
+		dst = append(dst, complements[seq[i]])
+	}
+	return dst
+}
+
+// Encode packs k-mer in 2 bits per base, the last base is in the lowest bits.
+// It returns false when seq is too long or contains bases other than A, C, G and T.
+func Encode(seq []byte) (uint64, bool) {
+	if len(seq) > MaxK {
+		return 0, false
+	}
+	var km uint64
+	for _, b := range seq {
+		c := baseCodes[b]
+		if c < 0 {
+			return 0, false
+		}
+		km = km<<2 | uint64(c)
+	}
+	return km, true
+}
+
+// Decode unpacks k-mer of length k.
+func Decode(km uint64, k int) []byte {
+	seq := make([]byte, k)
+	for i := k - 1; i >= 0; i-- {
+		seq[i] = "ACGT"[km&3]
+		km >>= 2
+	}
+	return seq
+}
+
+// ReverseComplement returns reverse complement of k-mer of length k.
+func ReverseComplement(km uint64, k int) uint64 {
+	var rc uint64
+	for i := 0; i < k; i++ {
+		rc = rc<<2 | (3 - km&3)
+		km >>= 2
+	}
+	return rc
+}
+
+// Canonical returns the smaller one of k-mer and its reverse complement.
+func Canonical(km uint64, k int) uint64 {
+	if rc := ReverseComplement(km, k); rc < km {
+		return rc
+	}
+	return km
+}
+
+// Contig represents a sequence of genome in index.
+type Contig struct {
+	Name   string
+	Offset uint64 // Offset in concatenated genome.
+	Length uint64
+}
+
+// Position represents a position of k-mer on forward strand of genome.
+type Position struct {
+	Chr string
+	Pos int64
+}
+
+// Index represents k-mers of forward strand of genome that are sorted with
+// their positions in concatenated genome, it takes 12 bytes per k-mer.
+type Index struct {
+	K       int
+	Contigs []Contig
+	kmers   []uint64
+	pos     []uint32
+}
+
+// Len returns the number of k-mers in index.
+func (idx *Index) Len() int {
+	return len(idx.kmers)
+}
+
+// sorter sorts k-mers with their positions.
+type sorter struct {
+	*Index
+}
+
+func (s sorter) Less(i, j int) bool {
+	if s.kmers[i] != s.kmers[j] {
+		return s.kmers[i] < s.kmers[j]
+	}
+	return s.pos[i] < s.pos[j]
+}
+
+func (s sorter) Swap(i, j int) {
+	s.kmers[i], s.kmers[j] = s.kmers[j], s.kmers[i]
+	s.pos[i], s.pos[j] = s.pos[j], s.pos[i]
+}
+
+// Build builds index of k-mers of sequences by names, contigs are in natural
+// order and k-mers that contain bases other than A, C, G and T are skipped.
+func Build(seqs map[string][]byte, k int) (*Index, error) {
+	if k < 1 || k > MaxK {
+		return nil, ErrInvalidK
+	}
+	names := make([]string, 0, len(seqs))
+	var total uint64
+	for name, seq := range seqs {
+		names = append(names, name)
+		total += uint64(len(seq))
+	}
+	if total > math.MaxUint32 {
+		return nil, ErrTooLarge
+	}
+	cytomap.SortChrs(names)
+
+	idx := &Index{
+		K:       k,
+		Contigs: make([]Contig, 0, len(names)),
+		kmers:   make([]uint64, 0, total),
+		pos:     make([]uint32, 0, total),
+	}
+	mask := uint64(math.MaxUint64)
+	if k < MaxK {
+		mask = 1<<(2*uint(k)) - 1
+	}
+	var offset uint64
+	for _, name := range names {
+		seq := seqs[name]
+		idx.Contigs = append(idx.Contigs, Contig{name, offset, uint64(len(seq))})
+
+		// Rolling k-mer with number of valid bases at the end.
+		var km uint64
+		valid := 0
+		for i, b := range seq {
+			c := baseCodes[b]
+			if c < 0 {
+				valid = 0
+				continue
+			}
+			km = (km<<2 | uint64(c)) & mask
+			if valid++; valid >= k {
+				idx.kmers = append(idx.kmers, km)
+				idx.pos = append(idx.pos, uint32(offset+uint64(i+1-k)))
+			}
+		}
+		offset += uint64(len(seq))
+	}
+	sort.Sort(sorter{idx})
+	return idx, nil
+}
+
+// search returns range of k-mer in sorted k-mers.
+func (idx *Index) search(km uint64) (int, int) {
+	lo := sort.Search(len(idx.kmers), func(i int) bool { return idx.kmers[i] >= km })
+	hi := lo + sort.Search(len(idx.kmers)-lo, func(i int) bool { return idx.kmers[lo+i] > km })
+	return lo, hi
+}
+
+// encode encodes seq that must have length of k-mers of index.
+func (idx *Index) encode(seq []byte) (uint64, bool) {
+	if len(seq) != idx.K {
+		return 0, false
+	}
+	return Encode(seq)
+}
+
+// Count returns the number of times k-mer appears in forward strand of genome,
+// it returns 0 when seq has different length or contains other bases.
+func (idx *Index) Count(seq []byte) int {
+	km, ok := idx.encode(seq)
+	if !ok {
+		return 0
+	}
+	lo, hi := idx.search(km)
+	return hi - lo
+}
+
+// CountCanonical returns the number of times k-mer appears in both strands of genome.
+func (idx *Index) CountCanonical(seq []byte) int {
+	km, ok := idx.encode(seq)
+	if !ok {
+		return 0
+	}
+	lo, hi := idx.search(km)
+	if rc := ReverseComplement(km, idx.K); rc != km {
+		rlo, rhi := idx.search(rc)
+		hi += rhi - rlo
+	}
+	return hi - lo
+}
+
+// Lookup returns positions of k-mer in forward strand of genome in order.
+func (idx *Index) Lookup(seq []byte) []Position {
+	km, ok := idx.encode(seq)
+	if !ok {
+		return nil
+	}
+	lo, hi := idx.search(km)
+	if lo == hi {
+		return nil
+	}
+	ps := make([]Position, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		ps = append(ps, idx.locate(uint64(idx.pos[i])))
+	}
+	return ps
+}
+
+// locate converts position in concatenated genome to position of contig.
+func (idx *Index) locate(p uint64) Position {
+	i := sort.Search(len(idx.Contigs), func(i int) bool {
+		return idx.Contigs[i].Offset+idx.Contigs[i].Length > p
+	})
+	if i == len(idx.Contigs) {
+		panic(fmt.Sprintf("kmer: position %d out of genome", p))
+	}
+	return Position{idx.Contigs[i].Name, int64(p - idx.Contigs[i].Offset)}
+}
@@ -0,0 +1,74 @@
+package kmer
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEncode(t *testing.T) {
+	Convey("Encode and decode k-mers", t, func() {
+		km, ok := Encode([]byte("ACGT"))
+		So(ok, ShouldBeTrue)
+		So(km, ShouldEqual, 0x1b)
+		So(string(Decode(km, 4)), ShouldEqual, "ACGT")
+
+		km, _ = Encode([]byte("aacc"))
+		So(string(Decode(ReverseComplement(km, 4), 4)), ShouldEqual, "GGTT")
+		km2, _ := Encode([]byte("GGTT"))
+		So(Canonical(km2, 4), ShouldEqual, km)
+		So(Canonical(km, 4), ShouldEqual, km)
+
+		_, ok = Encode([]byte("ACNT"))
+		So(ok, ShouldBeFalse)
+		_, ok = Encode(make([]byte, 33))
+		So(ok, ShouldBeFalse)
+
+		So(BaseCode('G'), ShouldEqual, 2)
+		So(BaseCode('t'), ShouldEqual, 3)
+		So(BaseCode('N'), ShouldEqual, -1)
+		So(string(AppendReverseComplement([]byte(">"), []byte("AACgtN-"))), ShouldEqual, ">-NacGTT")
+	})
+}
+
+func TestIndex(t *testing.T) {
+	Convey("Build index and look up k-mers", t, func() {
+		seqs := map[string][]byte{
+			"chr2":  []byte("ACGTNACGT"),
+			"chr1":  []byte("ttACGTaa"),
+			"chr10": []byte("AC"),
+		}
+		idx, err := Build(seqs, 4)
+		So(err, ShouldBeNil)
+		So(idx.Len(), ShouldEqual, 5+0+2)
+		So(idx.Contigs, ShouldResemble, []Contig{
+			{"chr1", 0, 8}, {"chr2", 8, 9}, {"chr10", 17, 2},
+		})
+
+		So(idx.Count([]byte("ACGT")), ShouldEqual, 3)
+		So(idx.Lookup([]byte("acgt")), ShouldResemble, []Position{
+			{"chr1", 2}, {"chr2", 0}, {"chr2", 5},
+		})
+		So(idx.Count([]byte("TTAC")), ShouldEqual, 1)
+		So(idx.Count([]byte("CCCC")), ShouldEqual, 0)
+		So(idx.Lookup([]byte("CCCC")), ShouldBeNil)
+		So(idx.Count([]byte("ACG")), ShouldEqual, 0)
+		So(idx.Count([]byte("ACNT")), ShouldEqual, 0)
+
+		// ACGT is palindromic, TTAC is reverse complement of GTAA.
+		So(idx.CountCanonical([]byte("ACGT")), ShouldEqual, 3)
+		So(idx.CountCanonical([]byte("GTAA")), ShouldEqual, 2)
+		So(idx.CountCanonical([]byte("TTAC")), ShouldEqual, 2)
+		So(idx.CountCanonical([]byte("GGGG")), ShouldEqual, 0)
+
+		_, err = Build(seqs, 0)
+		So(err, ShouldEqual, ErrInvalidK)
+		_, err = Build(seqs, 33)
+		So(err, ShouldEqual, ErrInvalidK)
+
+		idx, err = Build(map[string][]byte{"chr1": []byte("ACGTACGTACGTACGTACGTACGTACGTACGTA")}, 32)
+		So(err, ShouldBeNil)
+		So(idx.Count([]byte("CGTACGTACGTACGTACGTACGTACGTACGTA")), ShouldEqual, 1)
+		So(idx.Lookup([]byte("ACGTACGTACGTACGTACGTACGTACGTACGT")), ShouldResemble, []Position{{"chr1", 0}})
+	})
+}
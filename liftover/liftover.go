@@ -0,0 +1,227 @@
+// Package liftover converts coordinates between genome assemblies by UCSC chain files.
+package liftover
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/interval"
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// DefaultMinMatch is the default minimum fraction of bases that must be lifted.
+const DefaultMinMatch = 0.95
+
+// chain represents header of a chain, names are in UCSC style.
+type chain struct {
+	tName, qName string
+	qSize        int64
+	qStrand      byte
+}
+
+// block represents an ungapped aligned block of a chain.
+type block struct {
+	chain        int
+	tStart, tEnd int64
+	qStart       int64 // Start on the strand of query.
+}
+
+// Chains represents chains from source assembly (target of chain file)
+// to destination assembly (query of chain file).
+type Chains struct {
+	MinMatch float64 // Minimum fraction of bases of range that must be lifted.
+	chains   []chain
+	blocks   []block
+	index    *interval.Index
+}
+
+// ReadChain reads chains in UCSC chain format.
+func ReadChain(r io.Reader) (*Chains, error) {
+	c := &Chains{MinMatch: DefaultMinMatch, index: interval.NewIndex()}
+	snr := bufio.NewScanner(r)
+	var (
+		inChain bool
+		t, q    int64 // Current positions of target and query.
+	)
+	for line := 1; snr.Scan(); line++ {
+		text := strings.TrimSpace(snr.Text())
+		if len(text) == 0 || text[0] == '#' {
+			inChain = false
+			continue
+		}
+		fields := strings.Fields(text)
+
+		if fields[0] == "chain" {
+			if len(fields) < 12 {
+				return nil, fmt.Errorf("liftover: line %d: invalid chain header: %s", line, text)
+			}
+			nums := make([]int64, 0, 4)
+			for _, i := range []int{5, 8, 10, 11} {
+				v, err := strconv.ParseInt(fields[i], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("liftover: line %d: %v", line, err)
+				}
+				nums = append(nums, v)
+			}
+			if len(fields[9]) != 1 || (fields[9][0] != '+' && fields[9][0] != '-') {
+				return nil, fmt.Errorf("liftover: line %d: invalid strand: %s", line, fields[9])
+			}
+			c.chains = append(c.chains, chain{
+				tName:   cytomap.NormalizeChr(fields[2]),
+				qName:   cytomap.NormalizeChr(fields[7]),
+				qSize:   nums[1],
+				qStrand: fields[9][0],
+			})
+			t, q = nums[0], nums[2]
+			inChain = true
+			continue
+		}
+
+		if !inChain {
+			return nil, fmt.Errorf("liftover: line %d: alignment data without chain header", line)
+		}
+		if len(fields) != 1 && len(fields) != 3 {
+			return nil, fmt.Errorf("liftover: line %d: invalid alignment data: %s", line, text)
+		}
+		nums := make([]int64, len(fields))
+		for i, f := range fields {
+			v, err := strconv.ParseInt(f, 10, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("liftover: line %d: invalid number: %s", line, f)
+			}
+			nums[i] = v
+		}
+		id := len(c.chains) - 1
+		c.blocks = append(c.blocks, block{chain: id, tStart: t, tEnd: t + nums[0], qStart: q})
+		c.index.Add(c.chains[id].tName, t, t+nums[0])
+		t, q = t+nums[0], q+nums[0]
+		if len(nums) == 3 {
+			t, q = t+nums[1], q+nums[2]
+		} else {
+			// The last block of chain.
+			inChain = false
+		}
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+	c.index.Build()
+	return c, nil
+}
+
+// lift converts range [start, end) and returns index of chain that is used,
+// it returns -1 when range cannot be lifted.
+func (c *Chains) lift(chr string, start, end int64) (int, int64, int64) {
+	if end <= start {
+		return -1, 0, 0
+	}
+
+	// Pick the chain that covers most bases.
+	covered := make(map[int]int64)
+	ids := c.index.Overlap(cytomap.NormalizeChr(chr), start, end)
+	best := -1
+	for _, id := range ids {
+		b := c.blocks[id]
+		covered[b.chain] += min64(end, b.tEnd) - max64(start, b.tStart)
+		if best < 0 || covered[b.chain] > covered[best] ||
+			(covered[b.chain] == covered[best] && b.chain < best) {
+			best = b.chain
+		}
+	}
+	if best < 0 || float64(covered[best]) < c.MinMatch*float64(end-start) {
+		return -1, 0, 0
+	}
+
+	qStart, qEnd := int64(-1), int64(-1)
+	for _, id := range ids {
+		b := c.blocks[id]
+		if b.chain != best {
+			continue
+		}
+		s := b.qStart + max64(start, b.tStart) - b.tStart
+		e := b.qStart + min64(end, b.tEnd) - b.tStart
+		if qStart < 0 || s < qStart {
+			qStart = s
+		}
+		if e > qEnd {
+			qEnd = e
+		}
+	}
+	if ch := c.chains[best]; ch.qStrand == '-' {
+		qStart, qEnd = ch.qSize-qEnd, ch.qSize-qStart
+	}
+	return best, qStart, qEnd
+}
+
+// Lift converts range [start, end) to destination assembly, it returns false
+// when fraction of lifted bases is less than MinMatch.
+func (c *Chains) Lift(chr string, start, end int64) (string, int64, int64, bool) {
+	id, s, e := c.lift(chr, start, end)
+	if id < 0 {
+		return "", 0, 0, false
+	}
+	return c.chains[id].qName, s, e, true
+}
+
+// LiftTiles converts coordinates of tiles to destination assembly, data of
+// tiles that are lifted to reverse strand is reverse complemented. Lifted tiles
+// are in the same order and tiles that cannot be lifted are nil, indexes of
+// them are returned.
+func (c *Chains) LiftTiles(tiles []*tileset.Tile) (lifted []*tileset.Tile, unmapped []int) {
+	lifted = make([]*tileset.Tile, len(tiles))
+	for i, t := range tiles {
+		id, s, e := c.lift(t.Chr, t.Start, t.End)
+		if id < 0 {
+			unmapped = append(unmapped, i)
+			continue
+		}
+		data := append([]byte(nil), t.Data...)
+		if c.chains[id].qStrand == '-' {
+			data = kmer.AppendReverseComplement(data[:0], t.Data)
+		}
+		lifted[i] = &tileset.Tile{Chr: c.chains[id].qName, Start: s, End: e, Data: data}
+	}
+	return lifted, unmapped
+}
+
+// LiftMap converts band ranges of cytomap to destination assembly, bands that
+// cannot be lifted are skipped and returned. Tiles of bands are not kept.
+func (c *Chains) LiftMap(cm *cytomap.CytoMap, to *cytomap.Assembly) (*cytomap.CytoMap, []*cytomap.CytoRule) {
+	lcm := &cytomap.CytoMap{Hg: to.Hg, Assembly: to, Rules: make([]*cytomap.CytoRule, 0, len(cm.Rules))}
+	var unmapped []*cytomap.CytoRule
+	for _, rule := range cm.Rules {
+		chr, s, e, ok := c.Lift(rule.Chr, rule.Start, rule.End)
+		if !ok {
+			unmapped = append(unmapped, rule)
+			continue
+		}
+		lcm.Rules = append(lcm.Rules, &cytomap.CytoRule{
+			Chr:     chr,
+			Start:   s,
+			End:     e,
+			Section: rule.Section,
+			Color:   rule.Color,
+		})
+	}
+	lcm.BuildIndex()
+	return lcm, unmapped
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package liftover
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Source chr1 [0, 1000) maps to chr1 [100, 1100) with a 10-base deletion
+// at 500, and source 2 [0, 100) maps to reverse strand of chr2 of size 1000.
+const chainData = `chain 1000 chr1 2000 + 0 1000 1 5000 + 100 1090 1
+500 10 0
+490
+
+chain 500 2 200 + 0 100 chr2 1000 - 0 100 2
+100
+`
+
+func TestLift(t *testing.T) {
+	Convey("Lift ranges by chains", t, func() {
+		c, err := ReadChain(strings.NewReader(chainData))
+		So(err, ShouldBeNil)
+
+		chr, s, e, ok := c.Lift("1", 10, 20)
+		So(ok, ShouldBeTrue)
+		So(chr, ShouldEqual, "chr1")
+		So(s, ShouldEqual, 110)
+		So(e, ShouldEqual, 120)
+
+		chr, s, e, ok = c.Lift("chr1", 600, 700)
+		So(ok, ShouldBeTrue)
+		So([]int64{s, e}, ShouldResemble, []int64{690, 790})
+
+		// Range across the deletion.
+		_, _, _, ok = c.Lift("chr1", 490, 520)
+		So(ok, ShouldBeFalse)
+		c.MinMatch = 0.5
+		_, s, e, ok = c.Lift("chr1", 490, 520)
+		So(ok, ShouldBeTrue)
+		So([]int64{s, e}, ShouldResemble, []int64{590, 610})
+		c.MinMatch = DefaultMinMatch
+
+		chr, s, e, ok = c.Lift("chr2", 10, 30)
+		So(ok, ShouldBeTrue)
+		So(chr, ShouldEqual, "chr2")
+		So([]int64{s, e}, ShouldResemble, []int64{970, 990})
+
+		_, _, _, ok = c.Lift("chr3", 10, 30)
+		So(ok, ShouldBeFalse)
+		_, _, _, ok = c.Lift("chr1", 10, 10)
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Lift tiles and bands", t, func() {
+		c, err := ReadChain(strings.NewReader(chainData))
+		So(err, ShouldBeNil)
+
+		lifted, unmapped := c.LiftTiles([]*tileset.Tile{
+			{Chr: "chr1", Start: 0, End: 4, Data: []byte("ACGT")},
+			{Chr: "chr1", Start: 1500, End: 1504, Data: []byte("ACGT")},
+			{Chr: "chr2", Start: 0, End: 4, Data: []byte("AACn")},
+		})
+		So(unmapped, ShouldResemble, []int{1})
+		So(lifted[0], ShouldResemble, &tileset.Tile{Chr: "chr1", Start: 100, End: 104, Data: []byte("ACGT")})
+		So(lifted[1], ShouldBeNil)
+		So(lifted[2], ShouldResemble, &tileset.Tile{Chr: "chr2", Start: 996, End: 1000, Data: []byte("nGTT")})
+
+		cm := &cytomap.CytoMap{Hg: 19, Rules: []*cytomap.CytoRule{
+			{Chr: "chr1", Start: 0, End: 500, Section: "p1", Color: "gneg"},
+			{Chr: "chr1", Start: 1000, End: 2000, Section: "q1", Color: "gpos50"},
+		}}
+		lcm, missed := c.LiftMap(cm, cytomap.HG38)
+		So(lcm.Assembly, ShouldEqual, cytomap.HG38)
+		So(lcm.Hg, ShouldEqual, 38)
+		So(len(lcm.Rules), ShouldEqual, 1)
+		So(*lcm.Rules[0], ShouldResemble, cytomap.CytoRule{Chr: "chr1", Start: 100, End: 600, Section: "p1", Color: "gneg"})
+		So(missed, ShouldResemble, cm.Rules[1:])
+		So(lcm.Find("chr1", 200, 300), ShouldEqual, lcm.Rules[0])
+	})
+
+	Convey("Read invalid chain files", t, func() {
+		for _, data := range []string{
+			"chain 1000 chr1 2000 + 0 1000\n",
+			"100 10 0\n",
+			"chain 1000 chr1 2000 + 0 1000 chr1 5000 * 100 1090 1\n100\n",
+			"chain 1000 chr1 2000 + 0 1000 chr1 5000 + 100 1090 1\n100 10\n",
+			"chain 1000 chr1 2000 + 0 1000 chr1 5000 + 100 1090 1\n100\n10 1 1\n",
+		} {
+			_, err := ReadChain(strings.NewReader(data))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
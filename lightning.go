@@ -2,65 +2,219 @@
 package lightning
 
 import (
-	//"fmt"
-	//"errors"
+	"errors"
 
-	_ "github.com/genomelightning/lightning/bits"
-	_ "github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/align"
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/genome"
 )
 
+// ErrShortSequence is returned when the second sequence runs out of blocks.
+var ErrShortSequence = errors.New("lightning: second sequence does not have enough blocks")
+
+// differ computes differences of blocks against a reference in order.
+type differ struct {
+	ref      *genome.Sequence
+	b        *bits.Builder
+	index    int
+	skipNum  int
+	isHasMix bool
+}
+
+func newDiffer(ref *genome.Sequence, length int) *differ {
+	return &differ{ref: ref, b: bits.NewBuilder(uint32(length))}
+}
+
+// next computes DiffType of next block of sample, and returns index of the
+// reference block that is compared with, or -1 when it is complex or unknown.
+func (d *differ) next(block *genome.Block) (bits.DiffType, int, error) {
+	// Complex.
+	if d.skipNum > 0 {
+		d.skipNum--
+		return bits.DT_COMPLEX, -1, nil
+	}
+
+	if d.index >= d.ref.Length() {
+		return 0, -1, ErrShortSequence
+	}
+
+	if !d.isHasMix {
+		d.skipNum = d.ref.Blocks[d.index].NumMixedTag
+	}
+
+	if d.skipNum > 0 {
+		d.isHasMix = true
+		return bits.DT_COMPLEX, -1, nil
+	}
+
+	// Invalid.
+	// NOTE: Here we assume invalid does not mix with complex.
+	if !block.Valid || !d.ref.Blocks[d.index].Valid {
+		return bits.DT_UNKNOWN, -1, nil
+	}
+
+	d.isHasMix = false
+
+	// Non-complex, only called bases are compared.
+	ref := d.ref.Blocks[d.index]
+	equal, n := genome.CompareCalled(block, ref)
+	d.index++
+	switch {
+	case n == 0 && len(block.Data) > 0 && len(block.Data) == len(ref.Data):
+		// All bases are masked in either block.
+		return bits.DT_UNKNOWN, -1, nil
+	case equal:
+		return bits.DT_DEFAULT, d.index - 1, nil
+	}
+	return bits.DT_SIMPLE, d.index - 1, nil
+}
+
 // ComputeDiffSeq compares two processed genome squences and computes bit sequence of differences.
-// func ComputeDiffSeq(gs1, gs2 *genome.Sequence) (*bits.Sequence, error) {
-// 	// TODO: Concern both of two sequences have midxed tags.
-
-// 	// NOTE: Current implementatin can only handle mixed tags appear in gs2.
-// 	bs := bits.New(uint32(gs1.Length()))
-
-// 	index := 0
-// 	skipNum := 0
-// 	isHasMix := false
-
-// 	for i := 0; i < gs1.Length(); i++ {
-// 		// Complex.
-// 		if skipNum > 0 {
-// 			skipNum--
-// 			//fmt.Println("DT_COMPLEX")
-// 			bs.Set(uint64(i), bits.DT_COMPLEX)
-// 			continue
-// 		}
-
-// 		if !isHasMix {
-// 			skipNum = gs2.Blocks[index].NumMixedTag
-// 		}
-
-// 		if skipNum > 0 {
-// 			isHasMix = true
-// 			bs.Set(uint64(i), bits.DT_COMPLEX)
-// 			//fmt.Println("DT_COMPLEX2")
-// 			continue
-// 		}
-
-// 		// Invalid.
-// 		// NOTE: Here we assume invalid does not mix with complex.
-// 		if !gs1.Blocks[i].Valid || !gs2.Blocks[index].Valid {
-// 			//fmt.Println("DT_UNKNOWN")
-// 			bs.Set(uint64(i), bits.DT_UNKNOWN)
-// 			continue
-// 		}
-
-// 		isHasMix = false
-
-// 		// Non-complex.
-// 		str1, str2 := string(gs1.Blocks[i].Data), string(gs2.Blocks[index].Data)
-// 		if str1 == str2 {
-// 			//fmt.Println("DT_DEFAULT")
-// 			bs.Set(uint64(i), bits.DT_DEFAULT)
-// 		} else {
-// 			//fmt.Println("DT_SIMPLE")
-// 			bs.Set(uint64(i), bits.DT_SIMPLE)
-// 		}
-// 		index++
-// 	}
-
-// 	return bs, nil
-// }
+func ComputeDiffSeq(gs1, gs2 *genome.Sequence) (*bits.Sequence, error) {
+	bss, err := ComputeDiffSeqs(gs1, gs2)
+	if err != nil {
+		return nil, err
+	}
+	return bss[0], nil
+}
+
+// ComputeDiffSeqs compares processed genome sequence against multiple references
+// in one pass, and computes bit sequences of differences for every reference in order.
+// Only called bases of blocks are compared, filter sequences by genome.Thresholds
+// to make blocks of low quality or too many masked bases DT_UNKNOWN.
+func ComputeDiffSeqs(gs *genome.Sequence, refs ...*genome.Sequence) ([]*bits.Sequence, error) {
+	// TODO: Concern both of two sequences have midxed tags.
+
+	// NOTE: Current implementatin can only handle mixed tags appear in references.
+	ds := make([]*differ, len(refs))
+	for i, ref := range refs {
+		ds[i] = newDiffer(ref, gs.Length())
+	}
+
+	for _, block := range gs.Blocks {
+		for _, d := range ds {
+			dt, _, err := d.next(block)
+			if err != nil {
+				return nil, err
+			}
+			d.b.Add(dt, 0, 0)
+		}
+	}
+
+	bss := make([]*bits.Sequence, len(ds))
+	for i, d := range ds {
+		bss[i] = d.b.Sequence()
+	}
+	return bss, nil
+}
+
+// ComputeDiffDiploid compares diploid genome sequence against reference and
+// computes bit sequence of differences, the pair of variant numbers of both
+// haplotypes is set for tiles that are not complex or unknown. Pairs of unphased
+// tiles are ordered from small to large. Variants numbers variants of tiles
+// of reference, and a new one is used when it is nil.
+func ComputeDiffDiploid(d *genome.Diploid, ref *genome.Sequence, vs *genome.Variants) (*bits.Sequence, error) {
+	if d.Haplotypes[0].Length() != d.Haplotypes[1].Length() {
+		return nil, genome.ErrLengthMismatch
+	} else if len(d.Phased) != d.Length() {
+		return nil, genome.ErrUnphased
+	}
+	if vs == nil {
+		vs = genome.NewVariants(ref)
+	}
+
+	ds := [2]*differ{newDiffer(ref, d.Length()), newDiffer(ref, d.Length())}
+	b := bits.NewBuilder(uint32(d.Length()))
+	for i := 0; i < d.Length(); i++ {
+		var (
+			dt   bits.DiffType
+			nums [2]int
+		)
+		for j, hd := range ds {
+			block := d.Haplotypes[j].Blocks[i]
+			hdt, idx, err := hd.next(block)
+			if err != nil {
+				return nil, err
+			}
+			// The tile takes the most significant DiffType of haplotypes.
+			if hdt > dt {
+				dt = hdt
+			}
+			// Masked bases are not part of variants, so the default tile is
+			// the reference variant and others match seen variants on called bases.
+			switch {
+			case hdt == bits.DT_DEFAULT:
+				nums[j] = 1
+			case idx >= 0:
+				if nums[j] = vs.Match(idx, block); nums[j] == 0 {
+					nums[j] = vs.Number(idx, block.Data)
+				}
+			}
+		}
+
+		if dt == bits.DT_COMPLEX || dt == bits.DT_UNKNOWN {
+			nums = [2]int{}
+		} else if !d.Phased[i] && nums[0] > nums[1] {
+			nums[0], nums[1] = nums[1], nums[0]
+		}
+		if err := b.Add(dt, nums[0], nums[1]); err != nil {
+			return nil, err
+		}
+	}
+	return b.Sequence(), nil
+}
+
+// ComputeAlignedDiffSeq compares two processed genome squences by aligning
+// blocks that differ, and computes bit sequence of differences and edits of
+// simple tiles. Blocks whose changes exceed thresholds of opt are complex.
+// Edits that touch bases that are not called are dropped, and simple tiles
+// without any edit left have no edits.
+func ComputeAlignedDiffSeq(gs1, gs2 *genome.Sequence, opt align.Options) (*bits.Sequence, []align.TileEdits, error) {
+	d := newDiffer(gs2, gs1.Length())
+	var tes []align.TileEdits
+	for i, block := range gs1.Blocks {
+		dt, idx, err := d.next(block)
+		if err != nil {
+			return nil, nil, err
+		}
+		if dt == bits.DT_SIMPLE {
+			edits, isComplex := align.Compare(gs2.Blocks[idx].Data, block.Data, opt)
+			if !isComplex {
+				edits = calledEdits(edits, gs2.Blocks[idx], block)
+			}
+			if isComplex {
+				dt = bits.DT_COMPLEX
+			} else if len(edits) > 0 {
+				tes = append(tes, align.TileEdits{Tile: uint64(i), Ref: idx, Edits: edits})
+			}
+		}
+		d.b.Add(dt, 0, 0)
+	}
+	return d.b.Sequence(), tes, nil
+}
+
+// calledEdits returns edits of alt against ref whose bases are called in
+// both blocks, edits that touch masked bases are dropped.
+func calledEdits(edits []align.Edit, ref, alt *genome.Block) []align.Edit {
+	rc, ac := ref.Called(), alt.Called()
+	called := func(mask []bool) bool {
+		for _, c := range mask {
+			if !c {
+				return false
+			}
+		}
+		return true
+	}
+
+	var kept []align.Edit
+	// Offset of edit in alt differs from ref by lengths of previous edits.
+	shift := 0
+	for _, e := range edits {
+		x, y := e.Offset, e.Offset+shift
+		shift += len(e.Alt) - len(e.Ref)
+		if called(rc[x:x+len(e.Ref)]) && called(ac[y:y+len(e.Alt)]) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
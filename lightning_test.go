@@ -6,6 +6,8 @@ import (
 
 	. "github.com/smartystreets/goconvey/convey"
 
+	"github.com/genomelightning/lightning/align"
+	"github.com/genomelightning/lightning/bits"
 	"github.com/genomelightning/lightning/genome"
 )
 
@@ -41,8 +43,8 @@ func TestComputeDiffSeq(t *testing.T) {
 			})
 			bs, err := ComputeDiffSeq(gs1, gs2)
 			So(err, ShouldBeNil)
-			So(bs.DumpAsType(), ShouldEqual,
-				"00000000000000000000000000000000\n")
+			So(bs.DumpWordsAsType(), ShouldEqual,
+				"000\n")
 		})
 
 		Convey("Genome sequences only contains 'Simple'", func() {
@@ -75,8 +77,8 @@ func TestComputeDiffSeq(t *testing.T) {
 			})
 			bs, err := ComputeDiffSeq(gs1, gs2)
 			So(err, ShouldBeNil)
-			So(bs.DumpAsType(), ShouldEqual,
-				"11100000000000000000000000000000\n")
+			So(bs.DumpWordsAsType(), ShouldEqual,
+				"111\n")
 		})
 
 		Convey("Genome sequences contains 'Simple' and 'Invalid'", func() {
@@ -109,8 +111,8 @@ func TestComputeDiffSeq(t *testing.T) {
 			})
 			bs, err := ComputeDiffSeq(gs1, gs2)
 			So(err, ShouldBeNil)
-			So(bs.DumpAsType(), ShouldEqual,
-				"31300000000000000000000000000000\n")
+			So(bs.DumpWordsAsType(), ShouldEqual,
+				"313\n")
 		})
 
 		Convey("Genome sequences contains 'Simple' and 'Unknown'", func() {
@@ -149,8 +151,160 @@ func TestComputeDiffSeq(t *testing.T) {
 			})
 			bs, err := ComputeDiffSeq(gs1, gs2)
 			So(err, ShouldBeNil)
-			So(bs.DumpAsType(), ShouldEqual,
-				"12210000000000000000000000000000\n")
+			So(bs.DumpWordsAsType(), ShouldEqual,
+				"1221\n")
+		})
+	})
+}
+
+func TestComputeDiffSeqs(t *testing.T) {
+	Convey("Compute DiffType sequences against multiple references", t, func() {
+		newSeq := func(valid []bool, mixed []int, data ...string) *genome.Sequence {
+			gs := &genome.Sequence{}
+			for i := range data {
+				gs.Blocks = append(gs.Blocks, &genome.Block{
+					Valid:       valid[i],
+					NumMixedTag: mixed[i],
+					Data:        []byte(data[i]),
+				})
+			}
+			return gs
+		}
+
+		gs := newSeq([]bool{true, true, true, true}, []int{0, 0, 0, 0}, "AC", "GT", "TT", "CC")
+		ref1 := newSeq([]bool{true, true, true, true}, []int{0, 0, 0, 0}, "AC", "GA", "TT", "CC")
+		ref2 := newSeq([]bool{true, true, false}, []int{0, 1, 0}, "AA", "GT", "TT")
+
+		bss, err := ComputeDiffSeqs(gs, ref1, ref2)
+		So(err, ShouldBeNil)
+		So(len(bss), ShouldEqual, 2)
+		So(bss[0].DumpWordsAsType(), ShouldEqual, "0100\n")
+		So(bss[1].DumpWordsAsType(), ShouldEqual, "1221\n")
+
+		bs, err := ComputeDiffSeq(gs, ref1)
+		So(err, ShouldBeNil)
+		So(bits.Equal(bs, bss[0]), ShouldBeTrue)
+
+		_, err = ComputeDiffSeqs(gs, newSeq([]bool{true}, []int{0}, "AC"))
+		So(err, ShouldEqual, ErrShortSequence)
+
+		// Blocks of low quality are unknown by thresholds.
+		gs.Blocks[3].Depth, gs.Blocks[3].Quality = 4, 15
+		th := genome.Thresholds{MinDepth: 5}
+		bs, err = ComputeDiffSeq(th.Filter(gs), ref1)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "0103\n")
+		th = genome.Thresholds{MinDepth: 3, MinQuality: 10}
+		bs, err = ComputeDiffSeq(th.Filter(gs), ref1)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "0100\n")
+		th.MinQuality = 20
+		bs, err = ComputeDiffSeq(th.Filter(gs), ref1)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "0103\n")
+	})
+
+	Convey("Compare only called bases of blocks with masked bases", t, func() {
+		gs := &genome.Sequence{Blocks: []*genome.Block{
+			{Valid: true, Data: []byte("ACGN")},
+			{Valid: true, Data: []byte("ACGT"), NoCalls: []genome.Range{{Start: 0, End: 2}}},
+			{Valid: true, Data: []byte("NNNN")},
+			{Valid: true, Data: []byte("TAGT"), NoCalls: []genome.Range{{Start: 0, End: 1}}},
+		}}
+		ref := &genome.Sequence{Blocks: []*genome.Block{
+			{Valid: true, Data: []byte("ACGT")},
+			{Valid: true, Data: []byte("TTGT")},
+			{Valid: true, Data: []byte("ACGT")},
+			{Valid: true, Data: []byte("ACGT")},
+		}}
+		bs, err := ComputeDiffSeq(gs, ref)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "0031\n")
+
+		th := genome.Thresholds{MaxNoCallFraction: 0.25}
+		bs, err = ComputeDiffSeq(th.Filter(gs), ref)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "0331\n")
+
+		// Bases that are 'N' count as masked in thresholds.
+		gs = &genome.Sequence{Blocks: []*genome.Block{{Valid: true, Data: []byte("ANNN")}}}
+		th = genome.Thresholds{MaxNoCallFraction: 0.5}
+		bs, err = ComputeDiffSeq(th.Filter(gs), ref)
+		So(err, ShouldBeNil)
+		So(bs.Get(0), ShouldEqual, bits.DT_UNKNOWN)
+	})
+}
+
+func TestComputeDiffDiploid(t *testing.T) {
+	Convey("Compute DiffType sequence of diploid genome", t, func() {
+		newSeq := func(data ...string) *genome.Sequence {
+			gs := &genome.Sequence{}
+			for _, d := range data {
+				gs.Blocks = append(gs.Blocks, &genome.Block{Valid: d != "N", Data: []byte(d)})
+			}
+			return gs
+		}
+
+		ref := newSeq("AC", "GT", "TT", "CC", "AA")
+		d, err := genome.NewDiploid(
+			newSeq("AC", "GA", "TA", "CC", "N"),
+			newSeq("AC", "GT", "TC", "CG", "AA"), true)
+		So(err, ShouldBeNil)
+		d.Phased[3] = false
+
+		vs := genome.NewVariants(ref)
+		vs.Number(3, []byte("CT"))
+		bs, err := ComputeDiffDiploid(d, ref, vs)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "01113\n")
+		for i, nums := range [][2]int{{1, 1}, {2, 1}, {2, 3}, {1, 3}, {0, 0}} {
+			So(bs.GetCombine(uint64(i)), ShouldEqual, bits.GetCombineTableIndex(nums[0], nums[1]))
+		}
+
+		// Masked bases do not make new variants.
+		d, err = genome.NewDiploid(newSeq("ACGTACGTAN", "ACGTACGTTN"), newSeq("ACGTACNTAC", "ACGTACGTTC"), true)
+		So(err, ShouldBeNil)
+		bs, err = ComputeDiffDiploid(d, newSeq("ACGTACGTAC", "ACGTACGTAC"), nil)
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "01\n")
+		So(bs.GetCombine(0), ShouldEqual, bits.GetCombineTableIndex(1, 1))
+		So(bs.GetCombine(1), ShouldEqual, bits.GetCombineTableIndex(2, 2))
+
+		d.Phased = nil
+		_, err = ComputeDiffDiploid(d, ref, nil)
+		So(err, ShouldEqual, genome.ErrUnphased)
+	})
+}
+
+func TestComputeAlignedDiffSeq(t *testing.T) {
+	Convey("Compute DiffType sequence with edits of simple tiles", t, func() {
+		newSeq := func(data ...string) *genome.Sequence {
+			gs := &genome.Sequence{}
+			for _, d := range data {
+				gs.Blocks = append(gs.Blocks, &genome.Block{Valid: d != "N", Data: []byte(d)})
+			}
+			return gs
+		}
+
+		gs1 := newSeq("ACGTACGT", "ACCTACGT", "TGCATGCA", "N")
+		gs2 := newSeq("ACGTACGT", "ACGTACGT", "ACGTACGT", "ACGT")
+		bs, tes, err := ComputeAlignedDiffSeq(gs1, gs2, align.Options{MaxDistance: 4, MaxEdits: 2})
+		So(err, ShouldBeNil)
+		So(bs.DumpWordsAsType(), ShouldEqual, "0123\n")
+		So(tes, ShouldResemble, []align.TileEdits{
+			{Tile: 1, Ref: 1, Edits: []align.Edit{{Type: align.ET_SNV, Offset: 2, Ref: []byte("G"), Alt: []byte("C")}}},
+		})
+
+		// Masked bases are not edits.
+		gs1 = newSeq("ACGTTCGTAN", "ACGTACGTANN", "ACGtACGTAC", "ACGTACGTNG")
+		gs1.Blocks[2].NoCalls = []genome.Range{{Start: 3, End: 4}}
+		gs2 = newSeq("ACGTACGTAC", "ACGTACGTAC", "ACGAACGTAC", "ACGTACGTAC")
+		bs, tes, err = ComputeAlignedDiffSeq(gs1, gs2, align.DefaultOptions)
+		So(err, ShouldBeNil)
+		// Tiles that differ stay simple when all edits touch masked bases.
+		So(bs.DumpWordsAsType(), ShouldEqual, "1101\n")
+		So(tes, ShouldResemble, []align.TileEdits{
+			{Tile: 0, Ref: 0, Edits: []align.Edit{{Type: align.ET_SNV, Offset: 4, Ref: []byte("A"), Alt: []byte("T")}}},
 		})
 	})
 }
@@ -0,0 +1,214 @@
+// Package pca computes principal components of tile variants of genomes.
+package pca
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/genome"
+)
+
+// ErrNoSample is returned when building matrix without any sample.
+var ErrNoSample = errors.New("pca: no sample is given")
+
+// column represents one-hot column of a tile variant.
+type column struct {
+	tile    int
+	rows    []int32 // Samples that have this variant.
+	unknown []int32 // Samples that are unknown at this tile, shared by columns of same tile.
+	mean    float64 // Mean over known samples.
+}
+
+// Matrix represents sparse one-hot matrix of tile variants, samples as rows.
+// Values are centered by column means, and unknown calls are treated as means.
+type Matrix struct {
+	Samples int
+	Tiles   []int // Tiles that have been kept, in order.
+	Skipped []int // Tiles that have been skipped for too many unknown calls.
+	columns []*column
+}
+
+// Cols returns the number of variant columns.
+func (m *Matrix) Cols() int {
+	return len(m.columns)
+}
+
+// call represents a variant call of a sample at a tile.
+type call struct {
+	row     int32
+	variant int // -1 for unknown.
+}
+
+// newMatrix builds matrix from calls grouped by tile. Variant 0 is
+// reference and does not have a column.
+func newMatrix(samples int, calls map[int][]call, maxUnknown float64) *Matrix {
+	tiles := make([]int, 0, len(calls))
+	for tile := range calls {
+		tiles = append(tiles, tile)
+	}
+	sort.Ints(tiles)
+
+	m := &Matrix{Samples: samples}
+	for _, tile := range tiles {
+		var unknown []int32
+		variants := make(map[int][]int32)
+		keys := make([]int, 0, 2)
+		for _, c := range calls[tile] {
+			switch {
+			case c.variant < 0:
+				unknown = append(unknown, c.row)
+			case c.variant > 0:
+				if _, ok := variants[c.variant]; !ok {
+					keys = append(keys, c.variant)
+				}
+				variants[c.variant] = append(variants[c.variant], c.row)
+			}
+		}
+
+		if float64(len(unknown)) > maxUnknown*float64(samples) {
+			m.Skipped = append(m.Skipped, tile)
+			continue
+		}
+		known := samples - len(unknown)
+		if len(keys) == 0 || known == 0 {
+			continue
+		}
+
+		m.Tiles = append(m.Tiles, tile)
+		sort.Ints(keys)
+		for _, key := range keys {
+			rows := variants[key]
+			m.columns = append(m.columns, &column{
+				tile:    tile,
+				rows:    rows,
+				unknown: unknown,
+				mean:    float64(len(rows)) / float64(known),
+			})
+		}
+	}
+	return m
+}
+
+// FromSequences builds matrix from bit sequences of differences against
+// same reference. Variants are distinguished by DiffType and combine index,
+// tiles that have more than maxUnknown fraction of DT_UNKNOWN are skipped.
+func FromSequences(seqs []bits.Bitmap, maxUnknown float64) (*Matrix, error) {
+	if len(seqs) == 0 {
+		return nil, ErrNoSample
+	}
+
+	calls := make(map[int][]call)
+	for i, s := range seqs {
+		if s.Len() != seqs[0].Len() {
+			return nil, bits.ErrLengthMismatch
+		}
+		row := int32(i)
+		s.ForEachNonDefault(func(j uint64, dt bits.DiffType, combine int) {
+			c := call{row, int(dt)<<4 | combine}
+			if dt == bits.DT_UNKNOWN {
+				c.variant = -1
+			}
+			calls[int(j)] = append(calls[int(j)], c)
+		})
+	}
+	return newMatrix(len(seqs), calls, maxUnknown), nil
+}
+
+// FromGenomes builds matrix from processed genome sequences that have same
+// tiling. Variants are distinguished by data of blocks, the most common one
+// of each tile is treated as reference, invalid blocks are unknown.
+func FromGenomes(seqs []*genome.Sequence, maxUnknown float64) (*Matrix, error) {
+	if len(seqs) == 0 {
+		return nil, ErrNoSample
+	}
+
+	calls := make(map[int][]call)
+	for tile := 0; tile < seqs[0].Length(); tile++ {
+		ids := make(map[string]int)
+		counts := make([]int, 1, 4)
+		cs := make([]call, len(seqs))
+		for i, s := range seqs {
+			if s.Length() != seqs[0].Length() {
+				return nil, errors.New("pca: genomes have different number of blocks")
+			}
+			cs[i].row = int32(i)
+			b := s.Blocks[tile]
+			if !b.Valid {
+				cs[i].variant = -1
+				continue
+			}
+			id, ok := ids[string(b.Data)]
+			if !ok {
+				id = len(counts)
+				ids[string(b.Data)] = id
+				counts = append(counts, 0)
+			}
+			counts[id]++
+			cs[i].variant = id
+		}
+
+		ref := 0
+		for id := range counts {
+			if counts[id] > counts[ref] {
+				ref = id
+			}
+		}
+		for i := range cs {
+			if cs[i].variant == ref {
+				cs[i].variant = 0
+			}
+		}
+		calls[tile] = cs
+	}
+	return newMatrix(len(seqs), calls, maxUnknown), nil
+}
+
+// mulVec computes centered X·v, where len(v) equals number of columns.
+func (m *Matrix) mulVec(v, out []float64) {
+	var shift float64
+	for i := range out {
+		out[i] = 0
+	}
+	for j, c := range m.columns {
+		for _, r := range c.rows {
+			out[r] += v[j]
+		}
+		mv := c.mean * v[j]
+		shift += mv
+		for _, r := range c.unknown {
+			out[r] += mv
+		}
+	}
+	for i := range out {
+		out[i] -= shift
+	}
+}
+
+// mulTVec computes centered Xᵀ·u, where len(u) equals number of samples.
+func (m *Matrix) mulTVec(u, out []float64) {
+	var sum float64
+	for _, x := range u {
+		sum += x
+	}
+	for j, c := range m.columns {
+		var in, unk float64
+		for _, r := range c.rows {
+			in += u[r]
+		}
+		for _, r := range c.unknown {
+			unk += u[r]
+		}
+		out[j] = in - c.mean*(sum-unk)
+	}
+}
+
+// TotalVariance returns sum of squares of all centered values.
+func (m *Matrix) TotalVariance() float64 {
+	var total float64
+	for _, c := range m.columns {
+		known := float64(m.Samples - len(c.unknown))
+		total += known * c.mean * (1 - c.mean)
+	}
+	return total
+}
@@ -0,0 +1,240 @@
+package pca
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// Options represents options of randomized solver.
+type Options struct {
+	Components int   // Number of principal components.
+	Oversample int   // Extra dimensions of random subspace, default is 10.
+	Iterations int   // Number of power iterations, default is 4.
+	Seed       int64 // Seed of random number generator.
+}
+
+// Result represents principal components of samples.
+type Result struct {
+	Scores        [][]float64 // Scores of samples, one row per sample.
+	Variances     []float64   // Variances of components.
+	TotalVariance float64     // Variance of all centered values.
+}
+
+// ExplainedRatio returns fraction of total variance explained by component i.
+func (r *Result) ExplainedRatio(i int) float64 {
+	if r.TotalVariance == 0 {
+		return 0
+	}
+	return r.Variances[i] / r.TotalVariance
+}
+
+// Compute computes top principal components by randomized subspace iteration,
+// the matrix is only accessed by products so no dense copy is made.
+func (m *Matrix) Compute(opt Options) (*Result, error) {
+	if opt.Components <= 0 {
+		return nil, fmt.Errorf("pca: invalid number of components: %d", opt.Components)
+	}
+	if opt.Oversample <= 0 {
+		opt.Oversample = 10
+	}
+	if opt.Iterations <= 0 {
+		opt.Iterations = 4
+	}
+
+	n := m.Samples
+	l := opt.Components + opt.Oversample
+	if l > n {
+		l = n
+	}
+	if opt.Components > l {
+		return nil, fmt.Errorf("pca: %d components for %d samples", opt.Components, n)
+	}
+
+	rnd := rand.New(rand.NewSource(opt.Seed))
+	q := make([][]float64, l)
+	for i := range q {
+		q[i] = make([]float64, n)
+		for j := range q[i] {
+			q[i][j] = rnd.NormFloat64()
+		}
+	}
+
+	// Power iterations: Q = orth((XXᵀ)Q).
+	tmp := make([]float64, m.Cols())
+	for it := 0; it < opt.Iterations; it++ {
+		for i := range q {
+			m.mulTVec(q[i], tmp)
+			m.mulVec(tmp, q[i])
+		}
+		orthonormalize(q)
+	}
+
+	// Project XXᵀ onto the subspace: T = Qᵀ(XXᵀ)Q.
+	w := make([]float64, n)
+	t := make([][]float64, l)
+	for i := range q {
+		t[i] = make([]float64, l)
+	}
+	for j := range q {
+		m.mulTVec(q[j], tmp)
+		m.mulVec(tmp, w)
+		for i := range q {
+			t[i][j] = dot(q[i], w)
+		}
+	}
+	vals, vecs := jacobiEigen(t)
+
+	k := opt.Components
+	r := &Result{
+		Scores:        make([][]float64, n),
+		Variances:     make([]float64, k),
+		TotalVariance: m.TotalVariance(),
+	}
+	div := float64(n - 1)
+	if div < 1 {
+		div = 1
+	}
+	r.TotalVariance /= div
+	for s := range r.Scores {
+		r.Scores[s] = make([]float64, k)
+	}
+	for c := 0; c < k; c++ {
+		sigma := math.Sqrt(math.Max(vals[c], 0))
+		r.Variances[c] = vals[c] / div
+		for s := 0; s < n; s++ {
+			var u float64
+			for i := range q {
+				u += q[i][s] * vecs[i][c]
+			}
+			r.Scores[s][c] = u * sigma
+		}
+	}
+	return r, nil
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// orthonormalize applies modified Gram-Schmidt to vectors,
+// vectors that are linearly dependent become zeros.
+func orthonormalize(vs [][]float64) {
+	for i := range vs {
+		for j := 0; j < i; j++ {
+			d := dot(vs[i], vs[j])
+			for k := range vs[i] {
+				vs[i][k] -= d * vs[j][k]
+			}
+		}
+		norm := math.Sqrt(dot(vs[i], vs[i]))
+		for k := range vs[i] {
+			if norm > 1e-12 {
+				vs[i][k] /= norm
+			} else {
+				vs[i][k] = 0
+			}
+		}
+	}
+}
+
+// jacobiEigen computes eigenvalues and eigenvectors of symmetric matrix a,
+// in descending order of eigenvalues. Eigenvectors are columns of vecs.
+func jacobiEigen(a [][]float64) (vals []float64, vecs [][]float64) {
+	n := len(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		var off float64
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off < 1e-22 {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if math.Abs(a[p][q]) < 1e-300 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	// Sort by eigenvalues in descending order.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && a[order[j]][order[j]] > a[order[j-1]][order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	vals = make([]float64, n)
+	vecs = make([][]float64, n)
+	for i := range vecs {
+		vecs[i] = make([]float64, n)
+	}
+	for c, o := range order {
+		vals[c] = a[o][o]
+		for i := 0; i < n; i++ {
+			vecs[i][c] = v[i][o]
+		}
+	}
+	return vals, vecs
+}
+
+// WriteTSV writes scores of samples in tab-separated format.
+func (r *Result) WriteTSV(w io.Writer, names []string) error {
+	if len(names) != len(r.Scores) {
+		return fmt.Errorf("pca: %d names for %d samples", len(names), len(r.Scores))
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("sample")
+	for i := range r.Variances {
+		fmt.Fprintf(bw, "\tPC%d", i+1)
+	}
+	bw.WriteString("\n")
+	for i, scores := range r.Scores {
+		bw.WriteString(names[i])
+		for _, score := range scores {
+			fmt.Fprintf(bw, "\t%.6g", score)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
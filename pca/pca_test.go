@@ -0,0 +1,97 @@
+package pca
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/genome"
+)
+
+func bitmaps(seqs []*bits.Sequence) []bits.Bitmap {
+	bms := make([]bits.Bitmap, len(seqs))
+	for i := range seqs {
+		bms[i] = seqs[i]
+	}
+	return bms
+}
+
+func TestFromSequences(t *testing.T) {
+	Convey("Build one-hot matrix from bit sequences", t, func() {
+		seqs := make([]*bits.Sequence, 4)
+		for i := range seqs {
+			seqs[i] = bits.New(10)
+		}
+		seqs[0].Set(1, bits.DT_SIMPLE, 1, 2)
+		seqs[1].Set(1, bits.DT_SIMPLE, 2, 2)
+		seqs[2].Set(1, bits.DT_SIMPLE, 1, 2)
+		seqs[0].Set(5, bits.DT_UNKNOWN, 0, 0)
+		seqs[1].Set(5, bits.DT_UNKNOWN, 0, 0)
+		seqs[2].Set(5, bits.DT_COMPLEX, 1, 2)
+
+		m, err := FromSequences(bitmaps(seqs), 0.25)
+		So(err, ShouldBeNil)
+		So(m.Tiles, ShouldResemble, []int{1})
+		So(m.Skipped, ShouldResemble, []int{5})
+		So(m.Cols(), ShouldEqual, 2)
+
+		_, err = FromSequences(nil, 0)
+		So(err, ShouldEqual, ErrNoSample)
+	})
+}
+
+func TestCompute(t *testing.T) {
+	Convey("Compute principal components of two populations", t, func() {
+		seqs := make([]*bits.Sequence, 20)
+		for i := range seqs {
+			seqs[i] = bits.New(200)
+			for j := 0; j < 50; j++ {
+				if (i < 10) == (j%2 == 0) {
+					seqs[i].Set(uint64(j*3), bits.DT_SIMPLE, 1, 2)
+				}
+			}
+			seqs[i].Set(uint64(150+i), bits.DT_COMPLEX, 1, 2)
+		}
+
+		m, err := FromSequences(bitmaps(seqs), 0.1)
+		So(err, ShouldBeNil)
+		r, err := m.Compute(Options{Components: 2, Seed: 1})
+		So(err, ShouldBeNil)
+		So(len(r.Scores), ShouldEqual, 20)
+		So(r.Variances[0], ShouldBeGreaterThan, r.Variances[1])
+		So(r.ExplainedRatio(0), ShouldBeGreaterThan, 0.5)
+
+		sign := math.Signbit(r.Scores[0][0])
+		for i := range seqs {
+			So(math.Signbit(r.Scores[i][0]) == sign, ShouldEqual, i < 10)
+		}
+
+		_, err = m.Compute(Options{})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Compute principal components from genomes", t, func() {
+		gs := make([]*genome.Sequence, 6)
+		for i := range gs {
+			gs[i] = &genome.Sequence{}
+			for j := 0; j < 8; j++ {
+				data := "ACGT"
+				if i%2 == 0 && j < 4 {
+					data = "ACGA"
+				}
+				gs[i].Blocks = append(gs[i].Blocks, &genome.Block{Valid: true, Data: []byte(data)})
+			}
+		}
+		gs[0].Blocks[7].Valid = false
+
+		m, err := FromGenomes(gs, 0.5)
+		So(err, ShouldBeNil)
+		So(m.Tiles, ShouldResemble, []int{0, 1, 2, 3})
+		r, err := m.Compute(Options{Components: 1, Seed: 1})
+		So(err, ShouldBeNil)
+		So(math.Abs(r.Scores[0][0]-r.Scores[2][0]), ShouldBeLessThan, 1e-6)
+		So(math.Signbit(r.Scores[0][0]), ShouldNotEqual, math.Signbit(r.Scores[1][0]))
+	})
+}
@@ -0,0 +1,72 @@
+package readmap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Record represents a read in FASTQ format.
+type Record struct {
+	Name string
+	Seq  []byte
+	Qual []byte // Phred quality scores with offset 33.
+}
+
+// ReadFASTQ reads records of FASTQ format data and calls fn for each of them,
+// record is reused after fn returns. Sequence and quality must be in a single
+// line respectively.
+func ReadFASTQ(r io.Reader, fn func(*Record) error) error {
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<24)
+	rec := &Record{}
+	line := 0
+	// next returns next line, empty lines are skipped before header.
+	next := func(header bool) ([]byte, bool) {
+		for snr.Scan() {
+			line++
+			if byts := bytes.TrimSpace(snr.Bytes()); len(byts) > 0 || !header {
+				return byts, true
+			}
+		}
+		return nil, false
+	}
+
+	for {
+		header, ok := next(true)
+		if !ok {
+			return snr.Err()
+		}
+		if header[0] != '@' {
+			return fmt.Errorf("readmap: line %d: invalid header: %s", line, header)
+		}
+		if fields := bytes.Fields(header[1:]); len(fields) > 0 {
+			rec.Name = string(fields[0])
+		} else {
+			rec.Name = ""
+		}
+
+		seq, ok1 := next(false)
+		plus, ok2 := next(false)
+		qual, ok3 := next(false)
+		if !ok1 || !ok2 || !ok3 {
+			if err := snr.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("readmap: line %d: incomplete record %s", line, rec.Name)
+		}
+		if len(plus) == 0 || plus[0] != '+' {
+			return fmt.Errorf("readmap: line %d: invalid separator: %s", line-1, plus)
+		}
+		if len(qual) != len(seq) {
+			return fmt.Errorf("readmap: line %d: length of quality %d differs from sequence %d",
+				line, len(qual), len(seq))
+		}
+		rec.Seq = append(rec.Seq[:0], seq...)
+		rec.Qual = append(rec.Qual[:0], qual...)
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package readmap
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReadFASTQ(t *testing.T) {
+	Convey("Read records in FASTQ format", t, func() {
+		var recs []Record
+		collect := func(rec *Record) error {
+			recs = append(recs, Record{rec.Name, append([]byte(nil), rec.Seq...), append([]byte(nil), rec.Qual...)})
+			return nil
+		}
+		err := ReadFASTQ(strings.NewReader("@r1 test\nACGT\n+\nIIII\n\n@r2\nGG\n+r2\n#I\n"), collect)
+		So(err, ShouldBeNil)
+		So(recs, ShouldResemble, []Record{
+			{"r1", []byte("ACGT"), []byte("IIII")},
+			{"r2", []byte("GG"), []byte("#I")},
+		})
+
+		for _, data := range []string{
+			"r1\nACGT\n+\nIIII\n",
+			"@r1\nACGT\n-\nIIII\n",
+			"@r1\nACGT\n+\nIII\n",
+			"@r1\nACGT\n+\n",
+		} {
+			So(ReadFASTQ(strings.NewReader(data), collect), ShouldNotBeNil)
+		}
+	})
+}
@@ -0,0 +1,452 @@
+// Package readmap assigns sequencing reads to tiles by tags they contain and
+// assembles consensus of tiles from pileup of reads.
+package readmap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/genomelightning/lightning/align"
+	"github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// ErrNoTag is returned when tiles do not have any tag to anchor reads.
+var ErrNoTag = errors.New("readmap: no tag in tiles")
+
+// Options represents options of mapping reads.
+type Options struct {
+	K        int // Length of tags that neighboring tiles share.
+	MinDepth int // Minimum depth of every base of valid tiles.
+	MinQual  int // Minimum Phred quality of bases that are counted.
+	MaxIndel int // Maximum length difference of read and reference between tags.
+	// Thresholds of differences of parts of reads that are piled up.
+	Align align.Options
+}
+
+// DefaultOptions is the default options of mapping reads.
+var DefaultOptions = Options{
+	K:        tileset.DefaultTagLen,
+	MinDepth: 3,
+	MinQual:  10,
+	MaxIndel: 8,
+	Align:    align.DefaultOptions,
+}
+
+// codeDel is the code of deleted base in pileup, after codes of bases.
+const codeDel = 4
+
+// pile represents pileup of reads of a tile.
+type pile struct {
+	counts [][5]uint16               // Bases and deletion at every base.
+	ins    map[int]map[string]uint16 // Bases that are inserted before base.
+}
+
+// event represents a base of read that is aligned to reference position, or
+// bases that are inserted before the position when ins is not nil.
+type event struct {
+	pos  int64
+	code int
+	ins  []byte
+}
+
+// anchor represents a tag of tile at offset of read.
+type anchor struct {
+	tile   int
+	offset int
+}
+
+// Mapper assigns reads to tiles, a read is anchored by unique tags of tiles
+// it contains in either strand, and parts of read between and around tags
+// are aligned to reference of tiles before they are piled up.
+type Mapper struct {
+	Mapped    int // Number of reads that are piled up.
+	Unmapped  int // Number of reads without tags.
+	Conflict  int // Number of reads whose tags disagree on position.
+	Unaligned int // Number of reads whose differences exceed thresholds.
+
+	opt    Options
+	tiles  []*tileset.Tile
+	idx    *kmer.Index
+	tags   map[kmer.Position]int // Position of tag to index of tile that starts with it.
+	piles  []*pile               // Pileups of tiles, allocated when hit.
+	events []event
+	rc     []byte
+	rq     []byte
+}
+
+// New returns a new mapper of tiles in tileset order. Tile that overlaps
+// previous one on same chromosome starts with a tag, tags that appear more
+// than once in both strands of reference are not used. Tags are looked up in
+// idx of reference, which is built from tiles when it is nil.
+func New(tiles []*tileset.Tile, idx *kmer.Index, opt Options) (*Mapper, error) {
+	if opt.K < 1 || opt.K > kmer.MaxK {
+		return nil, kmer.ErrInvalidK
+	}
+	if idx == nil {
+		var err error
+		if idx, err = kmer.Build(sequences(tiles), opt.K); err != nil {
+			return nil, err
+		}
+	} else if idx.K != opt.K {
+		return nil, fmt.Errorf("readmap: k-mer length %d of index differs from tag length %d", idx.K, opt.K)
+	}
+
+	m := &Mapper{
+		opt:   opt,
+		tiles: tiles,
+		idx:   idx,
+		tags:  make(map[kmer.Position]int),
+		piles: make([]*pile, len(tiles)),
+	}
+	for i := 1; i < len(tiles); i++ {
+		t, prev := tiles[i], tiles[i-1]
+		if t.Chr != prev.Chr || prev.End <= t.Start || len(t.Data) < opt.K {
+			continue
+		}
+		if idx.CountCanonical(t.Data[:opt.K]) == 1 {
+			m.tags[kmer.Position{Chr: t.Chr, Pos: t.Start}] = i
+		}
+	}
+	if len(m.tags) == 0 {
+		return nil, ErrNoTag
+	}
+	return m, nil
+}
+
+// sequences returns sequences of chromosomes that tiles cover in reference
+// coordinates, bases that are not in any tile are 'N'.
+func sequences(tiles []*tileset.Tile) map[string][]byte {
+	seqs := make(map[string][]byte)
+	for _, t := range tiles {
+		if t.Start < 0 || t.End-t.Start != int64(len(t.Data)) {
+			continue
+		}
+		seq := seqs[t.Chr]
+		for int64(len(seq)) < t.End {
+			seq = append(seq, 'N')
+		}
+		copy(seq[t.Start:], t.Data)
+		seqs[t.Chr] = seq
+	}
+	return seqs
+}
+
+// Add assigns a read to tiles, it tries forward strand first and then
+// reverse complement. It returns false when read is not piled up.
+func (m *Mapper) Add(rec *Record) bool {
+	anchors, conflict := m.anchors(rec.Seq)
+	seq, qual := rec.Seq, rec.Qual
+	if len(anchors) == 0 && !conflict {
+		m.rc = kmer.AppendReverseComplement(m.rc[:0], rec.Seq)
+		m.rq = m.rq[:0]
+		for i := len(rec.Qual) - 1; i >= 0; i-- {
+			m.rq = append(m.rq, rec.Qual[i])
+		}
+		anchors, conflict = m.anchors(m.rc)
+		seq, qual = m.rc, m.rq
+	}
+	if conflict {
+		m.Conflict++
+		return false
+	} else if len(anchors) == 0 {
+		m.Unmapped++
+		return false
+	}
+
+	m.events = m.events[:0]
+	if !m.align(anchors, seq, qual) {
+		m.Unaligned++
+		return false
+	}
+
+	// Range of reference positions of events, the tag is always in range.
+	lo := m.tiles[anchors[0].tile].Start
+	hi := lo
+	for _, e := range m.events {
+		if e.pos < lo {
+			lo = e.pos
+		} else if e.pos > hi {
+			hi = e.pos
+		}
+	}
+	tile := anchors[0].tile
+	chr := m.tiles[tile].Chr
+	for tile > 0 && m.tiles[tile-1].Chr == chr && m.tiles[tile-1].End > lo {
+		tile--
+	}
+	for ; tile < len(m.tiles) && m.tiles[tile].Chr == chr && m.tiles[tile].Start <= hi; tile++ {
+		m.pileup(tile)
+	}
+	m.Mapped++
+	return true
+}
+
+// anchors returns tags in seq in order of offsets, a k-mer is a tag when it
+// appears only once in reference and at the start of a tile. It returns true
+// when tags disagree on position.
+func (m *Mapper) anchors(seq []byte) ([]anchor, bool) {
+	var as []anchor
+	for i := 0; i+m.opt.K <= len(seq); i++ {
+		ps := m.idx.Lookup(seq[i : i+m.opt.K])
+		if len(ps) != 1 {
+			continue
+		}
+		t, ok := m.tags[ps[0]]
+		if !ok || m.idx.CountCanonical(seq[i:i+m.opt.K]) != 1 {
+			continue
+		}
+		if n := len(as); n > 0 {
+			prev, cur := m.tiles[as[n-1].tile], m.tiles[t]
+			d := cur.Start - prev.Start - int64(i-as[n-1].offset)
+			if cur.Chr != prev.Chr || cur.Start <= prev.Start ||
+				d > int64(m.opt.MaxIndel) || d < -int64(m.opt.MaxIndel) {
+				return nil, true
+			}
+		}
+		as = append(as, anchor{t, i})
+	}
+	return as, false
+}
+
+// align aligns parts of read between and around tags to reference and
+// collects events of read. It returns false when any part is complex.
+func (m *Mapper) align(as []anchor, seq, qual []byte) bool {
+	first, last := as[0], as[len(as)-1]
+	if first.offset > 0 &&
+		!m.alignFlank(first.tile, seq[:first.offset], qual[:first.offset], m.tiles[first.tile].Start, true) {
+		return false
+	}
+	for i := 0; i+1 < len(as); i++ {
+		a, b := as[i], as[i+1]
+		start, end := m.tiles[a.tile].Start, m.tiles[b.tile].Start
+		ref := m.reference(a.tile, start, end)
+		edits, isComplex := align.Compare(ref, seq[a.offset:b.offset], m.opt.Align)
+		if isComplex {
+			return false
+		}
+		m.collect(start, ref, seq[a.offset:b.offset], qual[a.offset:b.offset], edits)
+	}
+	return m.alignFlank(last.tile, seq[last.offset:], qual[last.offset:], m.tiles[last.tile].Start, false)
+}
+
+// alignFlank aligns part of read that ends at reference position pos when
+// left is true, or starts at pos otherwise. References whose lengths differ
+// from read by at most MaxIndel are tried, the one with the fewest changed
+// bases is used and ties are broken by smaller difference of lengths.
+func (m *Mapper) alignFlank(tile int, seq, qual []byte, pos int64, left bool) bool {
+	var (
+		best      []align.Edit
+		bestRef   []byte
+		bestStart int64
+		bestCost  = -1
+	)
+	for d := 0; d <= m.opt.MaxIndel; d++ {
+		// Changes of length difference d cost at least d.
+		if bestCost >= 0 && bestCost <= d {
+			break
+		}
+		for _, n := range [2]int{len(seq) - d, len(seq) + d} {
+			if n < 0 || (d == 0 && n != len(seq)) {
+				continue
+			}
+			start := pos
+			if left {
+				start = pos - int64(n)
+			}
+			ref := m.reference(tile, start, start+int64(n))
+			edits, isComplex := align.Compare(ref, seq, m.opt.Align)
+			if isComplex {
+				continue
+			}
+			cost := 0
+			for _, e := range edits {
+				if len(e.Ref) > len(e.Alt) {
+					cost += len(e.Ref)
+				} else {
+					cost += len(e.Alt)
+				}
+			}
+			if bestCost < 0 || cost < bestCost {
+				best, bestRef, bestStart, bestCost = edits, ref, start, cost
+			}
+		}
+	}
+	if bestCost < 0 {
+		return false
+	}
+	m.collect(bestStart, bestRef, seq, qual, best)
+	return true
+}
+
+// reference returns bases of reference in [start, end) on chromosome of tile
+// from tiles around it in upper case, bases that are not in any tile are 'N'.
+func (m *Mapper) reference(tile int, start, end int64) []byte {
+	ref := make([]byte, end-start)
+	for i := range ref {
+		ref[i] = 'N'
+	}
+	chr := m.tiles[tile].Chr
+	for tile > 0 && m.tiles[tile-1].Chr == chr && m.tiles[tile-1].End > start {
+		tile--
+	}
+	for ; tile < len(m.tiles) && m.tiles[tile].Chr == chr && m.tiles[tile].Start < end; tile++ {
+		t := m.tiles[tile]
+		for i, b := range t.Data {
+			p := t.Start + int64(i)
+			if p < start || p >= end {
+				continue
+			}
+			if b >= 'a' && b <= 'z' {
+				b -= 'a' - 'A'
+			}
+			ref[p-start] = b
+		}
+	}
+	return ref
+}
+
+// collect collects events of seq that is aligned to ref at reference position
+// start by edits. Bases of low quality are not collected, and neither are
+// insertions that have any of them.
+func (m *Mapper) collect(start int64, ref, seq, qual []byte, edits []align.Edit) {
+	called := func(i int) bool {
+		return kmer.BaseCode(seq[i]) >= 0 && int(qual[i])-33 >= m.opt.MinQual
+	}
+	base := func(x, y int) {
+		if called(y) {
+			m.events = append(m.events, event{pos: start + int64(x), code: kmer.BaseCode(seq[y])})
+		}
+	}
+
+	x, y := 0, 0
+	for _, e := range edits {
+		for ; x < e.Offset; x, y = x+1, y+1 {
+			base(x, y)
+		}
+		switch e.Type {
+		case align.ET_SNV, align.ET_MNP:
+			for i := range e.Ref {
+				base(x+i, y+i)
+			}
+		case align.ET_DEL:
+			for i := range e.Ref {
+				m.events = append(m.events, event{pos: start + int64(x+i), code: codeDel})
+			}
+		case align.ET_INS:
+			ok := true
+			for i := range e.Alt {
+				ok = ok && called(y+i)
+			}
+			if ok {
+				m.events = append(m.events, event{pos: start + int64(x), ins: seq[y : y+len(e.Alt)]})
+			}
+		}
+		x, y = x+len(e.Ref), y+len(e.Alt)
+	}
+	for ; x < len(ref); x, y = x+1, y+1 {
+		base(x, y)
+	}
+}
+
+// pileup counts events of current read in tile, insertions are counted when
+// they are inside of tile.
+func (m *Mapper) pileup(tile int) {
+	t := m.tiles[tile]
+	p := m.piles[tile]
+	if p == nil {
+		p = &pile{counts: make([][5]uint16, t.End-t.Start)}
+		m.piles[tile] = p
+	}
+	for _, e := range m.events {
+		pos := int(e.pos - t.Start)
+		switch {
+		case e.ins != nil:
+			if pos <= 0 || pos >= len(p.counts) {
+				continue
+			}
+			if p.ins == nil {
+				p.ins = make(map[int]map[string]uint16)
+			}
+			if p.ins[pos] == nil {
+				p.ins[pos] = make(map[string]uint16)
+			}
+			if n := p.ins[pos][string(e.ins)]; n < math.MaxUint16 {
+				p.ins[pos][string(e.ins)] = n + 1
+			}
+		case pos >= 0 && pos < len(p.counts):
+			if p.counts[pos][e.code] < math.MaxUint16 {
+				p.counts[pos][e.code]++
+			}
+		}
+	}
+}
+
+// Sequence returns tiled genome of consensus of tiles with mean depth of
+// every tile in two decimals. Bases whose depth is less than MinDepth are
+// masked as 'N', bases that most reads delete are removed and bases that more
+// than half of reads insert are added. Tiles that do not have any called base
+// are invalid. Quality of tile is the lowest Phred-scaled agreement of reads
+// with consensus of called bases.
+func (m *Mapper) Sequence() *genome.Sequence {
+	s := &genome.Sequence{Blocks: make([]*genome.Block, len(m.tiles))}
+	for i, p := range m.piles {
+		b := &genome.Block{Reason: genome.IR_MISSING}
+		s.Blocks[i] = b
+		if p == nil {
+			continue
+		}
+		b.Data = make([]byte, 0, len(p.counts))
+		b.Reason = genome.IR_NONE
+		total, qual := 0, math.MaxInt32
+		for j, counts := range p.counts {
+			best, depth := 0, 0
+			for c, n := range counts {
+				depth += int(n)
+				if n > counts[best] {
+					best = c
+				}
+			}
+			total += depth
+			if depth == 0 || depth < m.opt.MinDepth {
+				b.Data = append(b.Data, 'N')
+				continue
+			}
+			if ins := insertion(p.ins[j]); len(ins) > 0 && 2*int(p.ins[j][ins]) > depth {
+				b.Data = append(b.Data, ins...)
+			}
+			// Error rate with pseudo counts.
+			e := float64(depth-int(counts[best])+1) / float64(depth+2)
+			if q := int(-10 * math.Log10(e)); q < qual {
+				qual = q
+			}
+			if best != codeDel {
+				b.Data = append(b.Data, "ACGT"[best])
+			}
+		}
+		if len(p.counts) > 0 {
+			b.Depth = math.Round(float64(total)/float64(len(p.counts))*100) / 100
+		}
+		b.NoCalls = genome.NoCallRanges(b.Data)
+		if qual == math.MaxInt32 {
+			b.Reason = genome.IR_LOW_DEPTH
+		} else {
+			b.Valid, b.Quality = true, qual
+		}
+	}
+	return s
+}
+
+// insertion returns the most common inserted bases, ties are broken by
+// smaller bases.
+func insertion(counts map[string]uint16) string {
+	best := ""
+	for ins, n := range counts {
+		if len(best) == 0 || n > counts[best] || n == counts[best] && ins < best {
+			best = ins
+		}
+	}
+	return best
+}
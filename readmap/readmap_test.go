@@ -0,0 +1,156 @@
+package readmap
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/genome"
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tilegen"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func randomSeq(r *rand.Rand, n int) []byte {
+	seq := make([]byte, n)
+	for i := range seq {
+		seq[i] = "ACGT"[r.Intn(4)]
+	}
+	return seq
+}
+
+func TestMapper(t *testing.T) {
+	Convey("Map reads to tiles and build consensus", t, func() {
+		r := rand.New(rand.NewSource(1))
+		ref := map[string][]byte{"chr1": randomSeq(r, 1000), "chr2": randomSeq(r, 300)}
+		tiles, _, err := tilegen.Generate(ref, nil, tilegen.Options{K: 12, Spacing: 60})
+		So(err, ShouldBeNil)
+
+		sample := map[string][]byte{"chr1": append([]byte(nil), ref["chr1"]...), "chr2": ref["chr2"]}
+		// SNV in the middle of a tile, away from tags.
+		sample["chr1"][tiles[3].Start+30] = "CGTA"[bytes.IndexByte([]byte("ACGT"), sample["chr1"][tiles[3].Start+30])]
+		want := genome.Tile(sample, tiles, false)
+
+		opt := DefaultOptions
+		opt.K, opt.MinDepth, opt.MinQual = 12, 2, 20
+		m, err := New(tiles, nil, opt)
+		So(err, ShouldBeNil)
+		// Reads of 80 bases at every 5 bases in both strands, chr2 is not covered.
+		seq := sample["chr1"]
+		for i := 0; i+80 <= len(seq); i += 5 {
+			read := &Record{Seq: seq[i : i+80], Qual: bytes.Repeat([]byte("I"), 80)}
+			if i%10 == 5 {
+				read.Seq = kmer.AppendReverseComplement(nil, read.Seq)
+			}
+			m.Add(read)
+		}
+		So(m.Mapped, ShouldBeGreaterThan, 0)
+		So(m.Unmapped, ShouldEqual, 0)
+		So(m.Conflict, ShouldEqual, 0)
+
+		So(m.Add(&Record{Seq: randomSeq(r, 80), Qual: bytes.Repeat([]byte("I"), 80)}), ShouldBeFalse)
+		So(m.Unmapped, ShouldEqual, 1)
+
+		// Low quality bases are not counted.
+		So(m.Add(&Record{Seq: seq[:80], Qual: bytes.Repeat([]byte("#"), 80)}), ShouldBeTrue)
+		// Tags in wrong distance.
+		read := append(append([]byte(nil), tiles[1].Data[:12]...), tiles[3].Data[:12]...)
+		So(m.Add(&Record{Seq: read, Qual: bytes.Repeat([]byte("I"), len(read))}), ShouldBeFalse)
+		So(m.Conflict, ShouldEqual, 1)
+
+		s := m.Sequence()
+		So(s.Length(), ShouldEqual, len(tiles))
+		for i, b := range s.Blocks {
+			if tiles[i].Chr == "chr2" {
+				So(b.Valid, ShouldBeFalse)
+				So(b.Reason, ShouldEqual, genome.IR_MISSING)
+				So(b.Depth, ShouldEqual, 0)
+				continue
+			}
+			So(b.Depth, ShouldBeGreaterThan, 0)
+			if tiles[i].Start >= 80 && tiles[i].End <= int64(len(seq))-80 {
+				So(b.Valid, ShouldBeTrue)
+				So(string(b.Data), ShouldEqual, string(want.Blocks[i].Data))
+				So(b.Quality, ShouldBeGreaterThan, 0)
+			} else if len(b.NoCalls) > 0 {
+				// Bases of low depth at ends of chromosome are masked.
+				So(b.Valid, ShouldBeTrue)
+				So(bytes.IndexByte(b.Data, 'N'), ShouldEqual, b.NoCalls[0].Start)
+				equal, n := genome.CompareCalled(b, want.Blocks[i])
+				So(equal, ShouldBeTrue)
+				So(n, ShouldEqual, len(b.Data)-b.NumNoCalls())
+			}
+		}
+
+		_, err = New(tiles[:1], nil, Options{K: 12})
+		So(err, ShouldEqual, ErrNoTag)
+		_, err = New(tiles, nil, Options{K: 0})
+		So(err, ShouldNotBeNil)
+		_, err = New([]*tileset.Tile{}, nil, DefaultOptions)
+		So(err, ShouldEqual, ErrNoTag)
+		idx, err := kmer.Build(ref, 12)
+		So(err, ShouldBeNil)
+		_, err = New(tiles, idx, DefaultOptions)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestMapperIndel(t *testing.T) {
+	Convey("Map reads with indels to tiles", t, func() {
+		r := rand.New(rand.NewSource(2))
+		ref := map[string][]byte{"chr1": randomSeq(r, 1000)}
+		tiles, _, err := tilegen.Generate(ref, nil, tilegen.Options{K: 12, Spacing: 60})
+		So(err, ShouldBeNil)
+		idx, err := kmer.Build(ref, 12)
+		So(err, ShouldBeNil)
+
+		// Deletion of 3 bases in tile 4 and insertion of 2 bases in tile 7,
+		// away from tags.
+		del, ins := tiles[4].Start+30, tiles[7].Start+30
+		seq := append([]byte(nil), ref["chr1"][:del]...)
+		seq = append(seq, ref["chr1"][del+3:ins]...)
+		seq = append(seq, "TT"...)
+		seq = append(seq, ref["chr1"][ins:]...)
+		want := map[int]string{
+			4: string(tiles[4].Data[:30]) + string(tiles[4].Data[33:]),
+			7: string(tiles[7].Data[:30]) + "TT" + string(tiles[7].Data[30:]),
+		}
+
+		opt := DefaultOptions
+		opt.K = 12
+		m, err := New(tiles, idx, opt)
+		So(err, ShouldBeNil)
+		for i := 0; i+80 <= len(seq); i += 4 {
+			read := &Record{Seq: seq[i : i+80], Qual: bytes.Repeat([]byte("I"), 80)}
+			if i%8 == 4 {
+				read.Seq = kmer.AppendReverseComplement(nil, read.Seq)
+			}
+			So(m.Add(read), ShouldBeTrue)
+		}
+		So(m.Unaligned, ShouldEqual, 0)
+
+		s := m.Sequence()
+		for i, b := range s.Blocks {
+			if tiles[i].Start < 80 || tiles[i].End > int64(len(seq))-80 {
+				continue
+			}
+			So(b.Valid, ShouldBeTrue)
+			if data, ok := want[i]; ok {
+				So(string(b.Data), ShouldEqual, data)
+			} else {
+				So(string(b.Data), ShouldEqual, string(tiles[i].Data))
+			}
+		}
+
+		// Reads are not piled up when they differ too much from reference.
+		read := append([]byte(nil), seq[:80]...)
+		for i := 0; i < len(read); i += 3 {
+			read[i] = "CGTA"[bytes.IndexByte([]byte("ACGT"), read[i])]
+		}
+		copy(read[tiles[1].Start:], tiles[1].Data[:12])
+		So(m.Add(&Record{Seq: read, Qual: bytes.Repeat([]byte("I"), 80)}), ShouldBeFalse)
+		So(m.Unaligned, ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,385 @@
+// Package server is a HTTP query server over tiled genome store.
+//
+// All responses are in JSON format and have ETag for caching:
+//
+//	GET /api/bands?chr=&start=&end=    bands that overlap region, all bands without query
+//	GET /api/tiles?chr=&start=&end=    tiles that overlap region, or by band=chr:name
+//	GET /api/tiles/{id}                coordinates and sequence of tile
+//	GET /api/tiles/{id}/summary        counts of DiffTypes and combinations in cohort
+//	GET /api/samples                   names of samples
+//	GET /api/samples/{name}/diff       spans of DiffTypes, range by start=&end= or band=
+package server
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// DiffExt is the file extension of serialized bit sequences.
+const DiffExt = ".bs"
+
+// Server represents a query server, data must not be changed after created.
+type Server struct {
+	cm      *cytomap.CytoMap
+	tiles   []*tileset.Tile
+	samples map[string]bits.Bitmap
+	names   []string
+	bands   []*Band
+	mux     *http.ServeMux
+}
+
+// Band represents a cytoband with range of tiles in it.
+type Band struct {
+	Chr       string `json:"chr"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Name      string `json:"name"`
+	Stain     string `json:"stain"`
+	TileStart int    `json:"tile_start"` // Index of first tile.
+	TileEnd   int    `json:"tile_end"`   // Index after last tile, equals to TileStart when no tile.
+}
+
+// New creates a new server, tiles are in the same order of bit sequences,
+// cm can be nil when band lookup is not needed.
+func New(cm *cytomap.CytoMap, tiles []*tileset.Tile, samples map[string]bits.Bitmap) (*Server, error) {
+	s := &Server{
+		cm:      cm,
+		tiles:   tiles,
+		samples: samples,
+		mux:     http.NewServeMux(),
+	}
+	for name, seq := range samples {
+		if int(seq.Len()) != len(tiles) {
+			return nil, fmt.Errorf("server: sample %s has %d tiles but library has %d",
+				name, seq.Len(), len(tiles))
+		}
+		s.names = append(s.names, name)
+	}
+	sort.Strings(s.names)
+
+	if cm != nil {
+		s.bands = make([]*Band, len(cm.Rules))
+		idx := make(map[*cytomap.CytoRule]*Band, len(cm.Rules))
+		for i, rule := range cm.Rules {
+			s.bands[i] = &Band{rule.Chr, rule.Start, rule.End, rule.Section, rule.Color, -1, -1}
+			idx[rule] = s.bands[i]
+		}
+		for i, t := range tiles {
+			b := idx[cm.Find(t.Chr, t.Start, t.End)]
+			if b == nil {
+				continue
+			}
+			if b.TileStart < 0 {
+				b.TileStart = i
+			}
+			b.TileEnd = i + 1
+		}
+		for _, b := range s.bands {
+			if b.TileStart < 0 {
+				b.TileStart, b.TileEnd = 0, 0
+			}
+		}
+	}
+
+	s.mux.HandleFunc("/api/bands", s.handleBands)
+	s.mux.HandleFunc("/api/tiles", s.handleTiles)
+	s.mux.HandleFunc("/api/tiles/", s.handleTile)
+	s.mux.HandleFunc("/api/samples", s.handleSamples)
+	s.mux.HandleFunc("/api/samples/", s.handleSample)
+	return s, nil
+}
+
+// LoadSamples loads all serialized bit sequences in directory, either in dense
+// or compressed form, sample name is file name without extension.
+func LoadSamples(dir string) (map[string]bits.Bitmap, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*"+DiffExt))
+	if err != nil {
+		return nil, err
+	}
+	samples := make(map[string]bits.Bitmap, len(names))
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		seq, err := bits.ReadBitmap(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		samples[strings.TrimSuffix(filepath.Base(name), DiffExt)] = seq
+	}
+	return samples, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// writeJSON writes value in JSON format with ETag, and responses
+// not modified when ETag matches If-None-Match of request.
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sum := sha1.Sum(data)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if match := r.Header.Get("If-None-Match"); len(match) > 0 {
+		for _, m := range strings.Split(match, ",") {
+			if m = strings.TrimSpace(m); m == etag || m == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(map[string]string{"error": msg})
+	w.Write(buf.Bytes())
+}
+
+// region represents query of chromosome region.
+type region struct {
+	chr        string
+	start, end int64
+}
+
+// parseRegion parses chr, start and end of query, chr is in UCSC style
+// and end is maximum when absent. It returns nil when no chr is given.
+func parseRegion(r *http.Request) (*region, error) {
+	q := r.URL.Query()
+	chr := q.Get("chr")
+	if len(chr) == 0 {
+		return nil, nil
+	}
+	reg := &region{chr: cytomap.NormalizeChr(chr), end: 1<<63 - 1}
+	var err error
+	if v := q.Get("start"); len(v) > 0 {
+		if reg.start, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid start: %s", v)
+		}
+	}
+	if v := q.Get("end"); len(v) > 0 {
+		if reg.end, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid end: %s", v)
+		}
+	}
+	return reg, nil
+}
+
+func (reg *region) overlaps(chr string, start, end int64) bool {
+	return cytomap.NormalizeChr(chr) == reg.chr && start < reg.end && end > reg.start
+}
+
+func (s *Server) handleBands(w http.ResponseWriter, r *http.Request) {
+	reg, err := parseRegion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	bands := make([]*Band, 0, len(s.bands))
+	for _, b := range s.bands {
+		if reg == nil || reg.overlaps(b.Chr, b.Start, b.End) {
+			bands = append(bands, b)
+		}
+	}
+	writeJSON(w, r, bands)
+}
+
+// findBand finds band by name in format "chr:name".
+func (s *Server) findBand(name string) *Band {
+	for _, b := range s.bands {
+		if b.Chr+":"+b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Tile represents a tile in responses.
+type Tile struct {
+	ID    int    `json:"id"`
+	Chr   string `json:"chr"`
+	Start int64  `json:"start"`
+	End   int64  `json:"end"`
+	Data  string `json:"data,omitempty"`
+}
+
+func (s *Server) handleTiles(w http.ResponseWriter, r *http.Request) {
+	start, end := 0, 0
+	if band := r.URL.Query().Get("band"); len(band) > 0 {
+		b := s.findBand(band)
+		if b == nil {
+			writeError(w, http.StatusNotFound, "band not found: "+band)
+			return
+		}
+		start, end = b.TileStart, b.TileEnd
+	} else {
+		reg, err := parseRegion(r)
+		if err != nil || reg == nil {
+			writeError(w, http.StatusBadRequest, "need chr or band")
+			return
+		}
+		start, end = s.tileRange(reg)
+	}
+
+	tiles := make([]*Tile, 0, end-start)
+	for i := start; i < end; i++ {
+		t := s.tiles[i]
+		tiles = append(tiles, &Tile{i, t.Chr, t.Start, t.End, ""})
+	}
+	writeJSON(w, r, tiles)
+}
+
+// tileRange returns range of tiles that overlap region,
+// tiles of a chromosome are assumed to be continuous and sorted.
+func (s *Server) tileRange(reg *region) (start, end int) {
+	start, end = -1, -1
+	for i, t := range s.tiles {
+		if reg.overlaps(t.Chr, t.Start, t.End) {
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+		}
+	}
+	if start < 0 {
+		return 0, 0
+	}
+	return start, end
+}
+
+// TileSummary represents counts of DiffTypes and combinations of a tile in cohort.
+type TileSummary struct {
+	ID       int            `json:"id"`
+	Samples  int            `json:"samples"`
+	Types    map[string]int `json:"types"`
+	Combines map[int]int    `json:"combines"` // Combine indexes of Simple and Complex tiles.
+}
+
+func (s *Server) handleTile(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/tiles/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id < 0 || id >= len(s.tiles) {
+		writeError(w, http.StatusNotFound, "tile not found: "+parts[0])
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		t := s.tiles[id]
+		writeJSON(w, r, &Tile{id, t.Chr, t.Start, t.End, string(t.Data)})
+	case len(parts) == 2 && parts[1] == "summary":
+		sum := &TileSummary{
+			ID:       id,
+			Samples:  len(s.names),
+			Types:    make(map[string]int),
+			Combines: make(map[int]int),
+		}
+		for _, name := range s.names {
+			seq := s.samples[name]
+			dt := seq.Get(uint64(id))
+			sum.Types[dt.String()]++
+			if dt == bits.DT_SIMPLE || dt == bits.DT_COMPLEX {
+				sum.Combines[seq.GetCombine(uint64(id))]++
+			}
+		}
+		writeJSON(w, r, sum)
+	default:
+		writeError(w, http.StatusNotFound, "not found: "+r.URL.Path)
+	}
+}
+
+func (s *Server) handleSamples(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, s.names)
+}
+
+// Span represents continuous tiles that have same DiffType.
+type Span struct {
+	Start int    `json:"start"` // Index of first tile.
+	End   int    `json:"end"`   // Index after last tile.
+	Type  string `json:"type"`
+}
+
+func (s *Server) handleSample(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/samples/"), "/")
+	if len(parts) != 2 || parts[1] != "diff" {
+		writeError(w, http.StatusNotFound, "not found: "+r.URL.Path)
+		return
+	}
+	seq, ok := s.samples[parts[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "sample not found: "+parts[0])
+		return
+	}
+
+	q := r.URL.Query()
+	start, end := 0, len(s.tiles)
+	if band := q.Get("band"); len(band) > 0 {
+		b := s.findBand(band)
+		if b == nil {
+			writeError(w, http.StatusNotFound, "band not found: "+band)
+			return
+		}
+		start, end = b.TileStart, b.TileEnd
+	}
+	var err error
+	if v := q.Get("start"); len(v) > 0 {
+		if start, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid start: "+v)
+			return
+		}
+	}
+	if v := q.Get("end"); len(v) > 0 {
+		if end, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid end: "+v)
+			return
+		}
+	}
+	if start < 0 || end > len(s.tiles) || start > end {
+		writeError(w, http.StatusBadRequest, "invalid tile range")
+		return
+	}
+
+	spans := make([]*Span, 0, 16)
+	var last *Span
+	for i := start; i < end; i++ {
+		dt := seq.Get(uint64(i)).String()
+		if last != nil && last.Type == dt {
+			last.End = i + 1
+			continue
+		}
+		last = &Span{i, i + 1, dt}
+		spans = append(spans, last)
+	}
+	writeJSON(w, r, spans)
+}
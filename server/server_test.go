@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/bits"
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func newTestServer() (*Server, error) {
+	cm := &cytomap.CytoMap{Hg: 19, Rules: []*cytomap.CytoRule{
+		{Chr: "chr1", Start: 0, End: 200, Section: "p36.33", Color: "gneg"},
+		{Chr: "chr1", Start: 200, End: 400, Section: "p36.32", Color: "gpos25"},
+	}}
+	tiles := make([]*tileset.Tile, 4)
+	for i := range tiles {
+		tiles[i] = &tileset.Tile{Chr: "chr1", Start: int64(i * 100), End: int64(i*100 + 100), Data: []byte("ACGT")}
+	}
+	a, b := bits.New(4), bits.New(4)
+	a.Set(1, bits.DT_SIMPLE, 1, 2)
+	a.Set(2, bits.DT_SIMPLE, 1, 2)
+	b.Set(1, bits.DT_COMPLEX, 2, 1)
+	return New(cm, tiles, map[string]bits.Bitmap{"a": a, "b": b})
+}
+
+func get(s *Server, url, etag string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	if len(etag) > 0 {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestServer(t *testing.T) {
+	Convey("Query tiled genome store by HTTP", t, func() {
+		s, err := newTestServer()
+		So(err, ShouldBeNil)
+
+		Convey("Look up bands", func() {
+			w := get(s, "/api/bands?chr=chr1&start=250&end=260", "")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			var bands []*Band
+			So(json.Unmarshal(w.Body.Bytes(), &bands), ShouldBeNil)
+			So(len(bands), ShouldEqual, 1)
+			So(bands[0].Name, ShouldEqual, "p36.32")
+			So(bands[0].TileStart, ShouldEqual, 2)
+			So(bands[0].TileEnd, ShouldEqual, 4)
+
+			etag := w.Header().Get("ETag")
+			So(etag, ShouldNotBeEmpty)
+			So(get(s, "/api/bands?chr=chr1&start=250&end=260", etag).Code, ShouldEqual, http.StatusNotModified)
+			So(get(s, "/api/bands?chr=chr1&start=250&end=260", `"x"`).Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Get tiles", func() {
+			var tiles []*Tile
+			w := get(s, "/api/tiles?band=chr1:p36.33", "")
+			So(json.Unmarshal(w.Body.Bytes(), &tiles), ShouldBeNil)
+			So(len(tiles), ShouldEqual, 2)
+
+			var tile Tile
+			w = get(s, "/api/tiles/3", "")
+			So(json.Unmarshal(w.Body.Bytes(), &tile), ShouldBeNil)
+			So(tile.Start, ShouldEqual, 300)
+			So(tile.Data, ShouldEqual, "ACGT")
+
+			So(get(s, "/api/tiles/4", "").Code, ShouldEqual, http.StatusNotFound)
+			So(get(s, "/api/tiles", "").Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Get summary of tile", func() {
+			var sum TileSummary
+			w := get(s, "/api/tiles/1/summary", "")
+			So(json.Unmarshal(w.Body.Bytes(), &sum), ShouldBeNil)
+			So(sum.Types, ShouldResemble, map[string]int{"simple": 1, "complex": 1})
+			So(sum.Combines, ShouldResemble, map[int]int{2: 1, 3: 1})
+		})
+
+		Convey("Get diff spans of sample", func() {
+			var spans []*Span
+			w := get(s, "/api/samples/a/diff", "")
+			So(json.Unmarshal(w.Body.Bytes(), &spans), ShouldBeNil)
+			So(spans, ShouldResemble, []*Span{{0, 1, "default"}, {1, 3, "simple"}, {3, 4, "default"}})
+
+			w = get(s, "/api/samples/a/diff?band=chr1:p36.32", "")
+			So(json.Unmarshal(w.Body.Bytes(), &spans), ShouldBeNil)
+			So(spans, ShouldResemble, []*Span{{2, 3, "simple"}, {3, 4, "default"}})
+
+			So(get(s, "/api/samples/c/diff", "").Code, ShouldEqual, http.StatusNotFound)
+			So(get(s, "/api/samples/a/diff?start=3&end=1", "").Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+
+	Convey("Create server with mismatched samples", t, func() {
+		_, err := New(nil, nil, map[string]bits.Bitmap{"a": bits.New(3)})
+		So(err, ShouldNotBeNil)
+	})
+}
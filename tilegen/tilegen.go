@@ -0,0 +1,136 @@
+// Package tilegen generates reference tileset from reference genome by
+// picking unique tags at roughly regular spacing.
+package tilegen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/genomelightning/lightning/cytomap"
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+// Options represents options of generating tileset.
+type Options struct {
+	K       int // Length of tags, at most 32.
+	Spacing int // Target distance between starts of neighboring tags.
+}
+
+// DefaultOptions is the default options of generating tileset.
+var DefaultOptions = Options{K: tileset.DefaultTagLen, Spacing: 200}
+
+// Tag represents a tag that is shared by neighboring tiles.
+type Tag struct {
+	Chr   string
+	Start int64
+	Seq   []byte
+}
+
+// Generate generates tiles and tags of sequences by names, chromosomes are in
+// natural order. Neighboring tiles share a unique tag of K bases, the first
+// tile of chromosome starts at 0 and the last one ends at end of chromosome.
+// Uniqueness of tags is checked in both strands by idx, which is built from
+// seqs when it is nil.
+func Generate(seqs map[string][]byte, idx *kmer.Index, opt Options) ([]*tileset.Tile, []*Tag, error) {
+	if opt.K < 1 || opt.K > kmer.MaxK {
+		return nil, nil, fmt.Errorf("tilegen: tag length must be in [1, %d]: %d", kmer.MaxK, opt.K)
+	}
+	if opt.Spacing < opt.K {
+		return nil, nil, fmt.Errorf("tilegen: spacing %d is less than tag length %d", opt.Spacing, opt.K)
+	}
+
+	if idx == nil {
+		var err error
+		if idx, err = kmer.Build(seqs, opt.K); err != nil {
+			return nil, nil, err
+		}
+	} else if idx.K != opt.K {
+		return nil, nil, fmt.Errorf("tilegen: k-mer length %d of index differs from tag length %d", idx.K, opt.K)
+	}
+
+	chrs := make([]string, 0, len(seqs))
+	for chr := range seqs {
+		chrs = append(chrs, chr)
+	}
+	cytomap.SortChrs(chrs)
+
+	var (
+		tiles []*tileset.Tile
+		tags  []*Tag
+	)
+	for _, chr := range chrs {
+		seq := seqs[chr]
+		if len(seq) == 0 {
+			continue
+		}
+		start := 0
+		for p := opt.Spacing; p+opt.K <= len(seq); p++ {
+			if idx.CountCanonical(seq[p:p+opt.K]) != 1 {
+				continue
+			}
+			tiles = append(tiles, &tileset.Tile{
+				Chr:   chr,
+				Start: int64(start),
+				End:   int64(p + opt.K),
+				Data:  bytes.ToUpper(seq[start : p+opt.K]),
+			})
+			tags = append(tags, &Tag{chr, int64(p), bytes.ToUpper(seq[p : p+opt.K])})
+			start = p
+			p += opt.Spacing - 1
+		}
+		tiles = append(tiles, &tileset.Tile{
+			Chr:   chr,
+			Start: int64(start),
+			End:   int64(len(seq)),
+			Data:  bytes.ToUpper(seq[start:]),
+		})
+	}
+	return tiles, tags, nil
+}
+
+// ShardName is the file name format of tileset shards.
+const ShardName = "tileset%04d.fa"
+
+// WriteShards writes tiles into tileset files in directory by ShardName with at
+// most size tiles per file, and returns names of files.
+func WriteShards(dir string, tiles []*tileset.Tile, size int) ([]string, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("tilegen: invalid shard size: %d", size)
+	}
+	var names []string
+	for i := 0; i*size < len(tiles); i++ {
+		end := (i + 1) * size
+		if end > len(tiles) {
+			end = len(tiles)
+		}
+		name := filepath.Join(dir, fmt.Sprintf(ShardName, i))
+		f, err := os.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if err = tileset.Write(f, tiles[i*size:end]); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if err = f.Close(); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// WriteTags writes tag library in tab-separated format.
+func WriteTags(w io.Writer, tags []*Tag) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "chr\tstart\ttag")
+	for _, t := range tags {
+		fmt.Fprintf(bw, "%s\t%d\t%s\n", t.Chr, t.Start, t.Seq)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,109 @@
+package tilegen
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/genomelightning/lightning/kmer"
+	"github.com/genomelightning/lightning/tileset"
+)
+
+func randomSeq(r *rand.Rand, n int) []byte {
+	seq := make([]byte, n)
+	for i := range seq {
+		seq[i] = "ACGT"[r.Intn(4)]
+	}
+	return seq
+}
+
+func TestGenerate(t *testing.T) {
+	Convey("Generate tileset with unique tags", t, func() {
+		r := rand.New(rand.NewSource(1))
+		repeat := randomSeq(r, 40)
+		chr1 := append(append(randomSeq(r, 15), repeat...), randomSeq(r, 300)...)
+		chr1 = append(chr1, repeat...)
+		seqs := map[string][]byte{
+			"chr10": randomSeq(r, 150),
+			"chr1":  bytes.ToLower(chr1),
+			"chr2":  randomSeq(r, 10),
+		}
+
+		opt := Options{K: 12, Spacing: 20}
+		tiles, tags, err := Generate(seqs, nil, opt)
+		So(err, ShouldBeNil)
+		So(len(tags), ShouldEqual, len(tiles)-3)
+		So(tiles[0].Chr, ShouldEqual, "chr1")
+		So(tiles[len(tiles)-1].Chr, ShouldEqual, "chr10")
+
+		// Tags in the repeat are skipped.
+		So(tags[0].Start, ShouldBeGreaterThanOrEqualTo, 15+40-12+1)
+
+		idx, err := kmer.Build(seqs, opt.K)
+		So(err, ShouldBeNil)
+		for _, tag := range tags {
+			So(idx.CountCanonical(tag.Seq), ShouldEqual, 1)
+		}
+		tiles2, tags2, err := Generate(seqs, idx, opt)
+		So(err, ShouldBeNil)
+		So(tiles2, ShouldResemble, tiles)
+		So(tags2, ShouldResemble, tags)
+
+		v := tileset.NewValidator(opt.K)
+		var buf bytes.Buffer
+		So(tileset.Write(&buf, tiles), ShouldBeNil)
+		So(v.Validate(&buf, "gen.fa"), ShouldBeNil)
+		So(v.Issues, ShouldBeEmpty)
+
+		for _, chr := range []string{"chr1", "chr2", "chr10"} {
+			var seq []byte
+			for _, t := range tiles {
+				if t.Chr == chr {
+					if len(seq) > 0 {
+						seq = seq[:len(seq)-opt.K]
+					}
+					seq = append(seq, t.Data...)
+				}
+			}
+			So(string(seq), ShouldEqual, strings.ToUpper(string(seqs[chr])))
+		}
+
+		_, _, err = Generate(seqs, nil, Options{K: 33, Spacing: 200})
+		So(err, ShouldNotBeNil)
+		_, _, err = Generate(seqs, nil, Options{K: 24, Spacing: 10})
+		So(err, ShouldNotBeNil)
+		_, _, err = Generate(seqs, idx, Options{K: 24, Spacing: 200})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Write shards and tag library", t, func() {
+		dir, err := os.MkdirTemp("", "tilegen")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		tiles := []*tileset.Tile{
+			{Chr: "chr1", Start: 0, End: 4, Data: []byte("ACGT")},
+			{Chr: "chr1", Start: 2, End: 6, Data: []byte("GTAC")},
+			{Chr: "chr1", Start: 4, End: 8, Data: []byte("ACGG")},
+		}
+		names, err := WriteShards(dir, tiles, 2)
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{
+			filepath.Join(dir, "tileset0000.fa"), filepath.Join(dir, "tileset0001.fa"),
+		})
+		tiles2, err := tileset.ReadFiles(names...)
+		So(err, ShouldBeNil)
+		So(tiles2, ShouldResemble, tiles)
+		_, err = WriteShards(dir, tiles, 0)
+		So(err, ShouldNotBeNil)
+
+		var buf bytes.Buffer
+		So(WriteTags(&buf, []*Tag{{"chr1", 2, []byte("GT")}}), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "chr\tstart\ttag\nchr1\t2\tGT\n")
+	})
+}
@@ -1,7 +1,116 @@
 // Package tileset handles tileset data file(.fa).
 package tileset
 
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
 // Tile represents a genome tile.
 type Tile struct {
-	Data []byte
+	Chr        string
+	Start, End int64 // Index, start from 0.
+	Data       []byte
+}
+
+// ParseHeader parses header line of tile in format ">chr:start-end".
+func ParseHeader(line string) (chr string, start, end int64, err error) {
+	infos := strings.Split(line, ":")
+	if len(infos) != 2 || len(infos[0]) < 2 || infos[0][0] != '>' {
+		return "", 0, 0, fmt.Errorf("tileset: invalid header: %s", line)
+	}
+	idxes := strings.Split(infos[1], "-")
+	if len(idxes) != 2 {
+		return "", 0, 0, fmt.Errorf("tileset: invalid header: %s", line)
+	}
+
+	chr = infos[0][1:]
+	if start, err = strconv.ParseInt(idxes[0], 10, 64); err != nil {
+		return "", 0, 0, err
+	}
+	if end, err = strconv.ParseInt(idxes[1], 10, 64); err != nil {
+		return "", 0, 0, err
+	}
+	return chr, start, end, nil
+}
+
+// Read reads tiles from tileset data in order.
+func Read(r io.Reader) ([]*Tile, error) {
+	var (
+		tiles []*Tile
+		tile  *Tile
+	)
+	buf := bytes.NewBufferString("")
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<30)
+	for snr.Scan() {
+		byts := snr.Bytes()
+		if len(byts) == 0 {
+			continue
+		}
+		if byts[0] != '>' {
+			if tile == nil {
+				return nil, fmt.Errorf("tileset: data before header: %s", byts)
+			}
+			buf.Write(byts)
+			continue
+		}
+
+		// Set for last tile data.
+		if tile != nil {
+			tile.Data = append([]byte(nil), buf.Bytes()...)
+			buf.Reset()
+		}
+
+		chr, start, end, err := ParseHeader(snr.Text())
+		if err != nil {
+			return nil, err
+		}
+		tile = &Tile{Chr: chr, Start: start, End: end}
+		tiles = append(tiles, tile)
+	}
+	if err := snr.Err(); err != nil {
+		return nil, err
+	}
+
+	// Set for last tile data.
+	if tile != nil {
+		tile.Data = append([]byte(nil), buf.Bytes()...)
+	}
+	return tiles, nil
+}
+
+// ReadFiles reads tiles from tileset files in order.
+func ReadFiles(names ...string) ([]*Tile, error) {
+	var tiles []*Tile
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		ts, err := Read(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		tiles = append(tiles, ts...)
+	}
+	return tiles, nil
+}
+
+// Write writes tiles in tileset format, every tile is a record with header
+// ">chr:start-end" and data in one line.
+func Write(w io.Writer, tiles []*Tile) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tiles {
+		fmt.Fprintf(bw, ">%s:%d-%d\n", t.Chr, t.Start, t.End)
+		bw.Write(t.Data)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
 }
@@ -0,0 +1,28 @@
+package tileset
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWrite(t *testing.T) {
+	Convey("Write and read tiles in tileset format", t, func() {
+		tiles := []*Tile{
+			{Chr: "chr1", Start: 0, End: 4, Data: []byte("ACGT")},
+			{Chr: "chr1", Start: 2, End: 8, Data: []byte("GTACGT")},
+		}
+		var buf bytes.Buffer
+		So(Write(&buf, tiles), ShouldBeNil)
+		So(buf.String(), ShouldEqual, ">chr1:0-4\nACGT\n>chr1:2-8\nGTACGT\n")
+
+		tiles2, err := Read(&buf)
+		So(err, ShouldBeNil)
+		So(tiles2, ShouldResemble, tiles)
+
+		_, err = Read(strings.NewReader("ACGT\n>chr1:0-4\nACGT\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,181 @@
+package tileset
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultTagLen is the default length of tags that neighboring tiles share.
+const DefaultTagLen = 24
+
+// Issue represents an inconsistency of tileset, line is the header line of tile.
+type Issue struct {
+	File       string
+	Line       int
+	Chr        string
+	Start, End int64
+	Msg        string
+}
+
+func (i Issue) String() string {
+	if len(i.Chr) == 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s:%d-%d: %s", i.File, i.Line, i.Chr, i.Start, i.End, i.Msg)
+}
+
+// Validator checks tiles of tileset files in order, tiles continue across files.
+// Neighboring tiles on the same chromosome share a tag, so the next tile starts
+// TagLen bases before the end of previous one and starts with its last TagLen bases.
+type Validator struct {
+	TagLen int
+	// Check returns message of inconsistency of tile that has valid header,
+	// or empty string for none. It is not called when nil.
+	Check  func(t *Tile) string
+	Issues []Issue
+	Tiles  int // Number of tiles that have been checked.
+
+	prev     *Tile
+	prevFile string
+	prevLine int
+	chrs     map[string]checked // Last tile of every chromosome.
+}
+
+// checked represents a checked tile, n is the number of tiles checked so far.
+type checked struct {
+	t    *Tile
+	file string
+	line int
+	n    int
+}
+
+// NewValidator returns a new validator with length of tags.
+func NewValidator(tagLen int) *Validator {
+	return &Validator{TagLen: tagLen}
+}
+
+func (v *Validator) report(name string, line int, t *Tile, format string, args ...interface{}) {
+	issue := Issue{File: name, Line: line, Msg: fmt.Sprintf(format, args...)}
+	if t != nil {
+		issue.Chr, issue.Start, issue.End = t.Chr, t.Start, t.End
+	}
+	v.Issues = append(v.Issues, issue)
+}
+
+// check checks tile with its data and relation with previous tile.
+func (v *Validator) check(name string, line int, t *Tile) {
+	v.Tiles++
+	if t.Start < 0 || t.End < t.Start {
+		v.report(name, line, t, "invalid range")
+	} else if n := t.End - t.Start; n != int64(len(t.Data)) {
+		v.report(name, line, t, "end-start is %d but sequence length is %d", n, len(t.Data))
+	}
+	if v.Check != nil {
+		if msg := v.Check(t); len(msg) > 0 {
+			v.report(name, line, t, "%s", msg)
+		}
+	}
+
+	// Tiles of a chromosome have to be consecutive, bad records in between are ignored.
+	if last, ok := v.chrs[t.Chr]; ok && last.n != v.Tiles-1 {
+		v.report(name, line, t, "chromosome appears again after its last tile %s:%d-%d at %s:%d",
+			last.t.Chr, last.t.Start, last.t.End, last.file, last.line)
+	}
+	if v.chrs == nil {
+		v.chrs = make(map[string]checked)
+	}
+	v.chrs[t.Chr] = checked{t, name, line, v.Tiles}
+
+	prev, prevFile, prevLine := v.prev, v.prevFile, v.prevLine
+	v.prev, v.prevFile, v.prevLine = t, name, line
+	if prev == nil || prev.Chr != t.Chr {
+		return
+	}
+	expect := prev.End - int64(v.TagLen)
+	switch {
+	case t.Start < expect:
+		v.report(name, line, t, "overlaps previous tile %s:%d-%d at %s:%d by %d bases",
+			prev.Chr, prev.Start, prev.End, prevFile, prevLine, expect-t.Start)
+	case t.Start > expect:
+		v.report(name, line, t, "gap of %d bases after previous tile %s:%d-%d at %s:%d",
+			t.Start-expect, prev.Chr, prev.Start, prev.End, prevFile, prevLine)
+	case v.TagLen > 0 && len(prev.Data) >= v.TagLen && len(t.Data) >= v.TagLen:
+		tag, end := t.Data[:v.TagLen], prev.Data[len(prev.Data)-v.TagLen:]
+		if !bytes.EqualFold(tag, end) {
+			v.report(name, line, t, "tag %s does not match end %s of previous tile %s:%d-%d at %s:%d",
+				tag, end, prev.Chr, prev.Start, prev.End, prevFile, prevLine)
+		}
+	}
+}
+
+// Validate checks tiles of tileset data, name is used for reporting issues.
+// It only returns error of reading, inconsistencies are collected as issues.
+func (v *Validator) Validate(r io.Reader, name string) error {
+	var (
+		tile *Tile
+		line int // Header line of current tile.
+	)
+	buf := bytes.NewBufferString("")
+	snr := bufio.NewScanner(r)
+	snr.Buffer(make([]byte, 64*1024), 1<<30)
+	for n := 1; snr.Scan(); n++ {
+		byts := snr.Bytes()
+		if len(byts) == 0 {
+			continue
+		}
+		if byts[0] != '>' {
+			if tile == nil && line == 0 {
+				v.report(name, n, nil, "sequence without header")
+				line = -1
+			}
+			buf.Write(byts)
+			continue
+		}
+
+		// Check last tile with its data.
+		if tile != nil {
+			tile.Data = append([]byte(nil), buf.Bytes()...)
+			v.check(name, line, tile)
+		}
+		buf.Reset()
+
+		tile, line = nil, n
+		chr, start, end, err := ParseHeader(snr.Text())
+		if err != nil {
+			v.report(name, n, nil, "%v", err)
+			// Next tile is not related to the tile before bad record.
+			v.prev = nil
+			continue
+		}
+		tile = &Tile{Chr: chr, Start: start, End: end}
+	}
+	if err := snr.Err(); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	// Check last tile with its data.
+	if tile != nil {
+		tile.Data = append([]byte(nil), buf.Bytes()...)
+		v.check(name, line, tile)
+	}
+	return nil
+}
+
+// ValidateFiles checks tiles of tileset files in order.
+func (v *Validator) ValidateFiles(names ...string) error {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = v.Validate(f, name)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package tileset
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidate(t *testing.T) {
+	Convey("Validate tileset with shared tags", t, func() {
+		v := NewValidator(2)
+		So(v.Validate(strings.NewReader(">chr1:0-6\nACGT\nAC\n>chr1:4-8\nACTT\n"), "a.fa"), ShouldBeNil)
+		So(v.Validate(strings.NewReader(">chr1:6-10\nTTGG\n>chr2:0-4\nACGT\n"), "b.fa"), ShouldBeNil)
+		So(v.Tiles, ShouldEqual, 4)
+		So(v.Issues, ShouldBeEmpty)
+	})
+
+	Convey("Report every inconsistency with file, line and coordinates", t, func() {
+		data := `>chr1:0-6
+ACGTAC
+>chr1:3-8
+CACGT
+>chr1:8-12
+ACGT
+>chr1:10-14
+GGGG
+>chr1:12-17
+GGCC
+>chr1-12
+AAAA
+`
+		v := NewValidator(2)
+		v.Check = func(t *Tile) string {
+			if t.Start == 0 {
+				return "bad tile"
+			}
+			return ""
+		}
+		So(v.Validate(strings.NewReader(data), "a.fa"), ShouldBeNil)
+		So(v.Tiles, ShouldEqual, 5)
+
+		issues := make([]string, len(v.Issues))
+		for i, issue := range v.Issues {
+			issues[i] = issue.String()
+		}
+		So(issues, ShouldResemble, []string{
+			"a.fa:1: chr1:0-6: bad tile",
+			"a.fa:3: chr1:3-8: overlaps previous tile chr1:0-6 at a.fa:1 by 1 bases",
+			"a.fa:5: chr1:8-12: gap of 2 bases after previous tile chr1:3-8 at a.fa:3",
+			"a.fa:7: chr1:10-14: tag GG does not match end GT of previous tile chr1:8-12 at a.fa:5",
+			"a.fa:9: chr1:12-17: end-start is 5 but sequence length is 4",
+			"a.fa:11: tileset: invalid header: >chr1-12",
+		})
+
+		v = NewValidator(0)
+		So(v.Validate(strings.NewReader("ACGT\nACGT\n>chr1:0-4\nACGT\n"), "b.fa"), ShouldBeNil)
+		So(len(v.Issues), ShouldEqual, 1)
+		So(v.Issues[0].String(), ShouldEqual, "b.fa:1: sequence without header")
+
+		// Tile after bad header is not compared with the tile before it.
+		v = NewValidator(2)
+		So(v.Validate(strings.NewReader(">chr1:0-6\nACGTAC\n>chr1-4\nACGGTT\n>chr1:8-12\nTTAA\n"), "c.fa"), ShouldBeNil)
+		So(len(v.Issues), ShouldEqual, 1)
+		So(v.Issues[0].String(), ShouldEqual, "c.fa:3: tileset: invalid header: >chr1-4")
+
+		// Chromosome that appears again after another one, also across files.
+		v = NewValidator(0)
+		So(v.Validate(strings.NewReader(">chr1:0-4\nACGT\n>chr2:0-4\nACGT\n"), "d.fa"), ShouldBeNil)
+		So(v.Validate(strings.NewReader(">chr1:4-8\nACGT\n>chr2:4-8\nACGT\n"), "e.fa"), ShouldBeNil)
+		So(len(v.Issues), ShouldEqual, 2)
+		So(v.Issues[0].String(), ShouldEqual, "e.fa:1: chr1:4-8: chromosome appears again after its last tile chr1:0-4 at d.fa:1")
+		So(v.Issues[1].String(), ShouldEqual, "e.fa:3: chr2:4-8: chromosome appears again after its last tile chr2:0-4 at d.fa:3")
+	})
+}